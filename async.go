@@ -0,0 +1,234 @@
+package gormzerolog
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// BackpressurePolicy defines what happens to a trace record traced while
+// the queue of the asynchronous emission is full.
+type BackpressurePolicy int
+
+const (
+	// Block waits for a free slot in the queue, slowing the traced statement
+	// down. It is the default.
+	Block BackpressurePolicy = iota
+	// DropNewest drops the record being traced.
+	DropNewest
+	// DropOldest drops the oldest queued record to make room for the new one.
+	DropOldest
+)
+
+// BackpressureConfig configures the handling of a full queue of
+// the asynchronous emission.
+type BackpressureConfig struct {
+	// Policy is the policy applied when the queue is full.
+	Policy BackpressurePolicy
+	// ReportInterval is a minimal period between warnings about dropped
+	// records. Default is 10 seconds.
+	ReportInterval time.Duration
+}
+
+type asyncEmitter struct {
+	mu      sync.RWMutex
+	running bool
+	queue   chan asyncItem
+	done    chan struct{}
+	cfg     BackpressureConfig
+
+	reportMu   sync.Mutex
+	lastReport time.Time
+	reported   uint64
+}
+
+type asyncItem struct {
+	// l is the logger which traced the record, so copies sharing the emitter
+	// emit records with their own configuration.
+	l       *GormLogger
+	rec     *TraceRecord
+	flushed chan struct{}
+}
+
+// enqueue puts a record traced by l into the queue following the backpressure policy
+// and returns the number of records dropped. It returns false if the emitter
+// doesn't accept records, so the caller has to emit the record by itself.
+func (a *asyncEmitter) enqueue(l *GormLogger, rec *TraceRecord) (queued bool, dropped uint64) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if !a.running {
+		return false, 0
+	}
+
+	item := asyncItem{l: l, rec: rec}
+	if a.cfg.Policy == Block {
+		a.queue <- item
+		return true, 0
+	}
+
+	for {
+		select {
+		case a.queue <- item:
+			return true, dropped
+		default:
+		}
+
+		if a.cfg.Policy == DropNewest {
+			return true, dropped + 1
+		}
+
+		select {
+		case oldest := <-a.queue:
+			switch {
+			case oldest.flushed == nil:
+				dropped++
+			case item.flushed == nil:
+				// keep the flush marker, which only moves later in the queue,
+				// and drop the new record instead
+				item = oldest
+				dropped++
+			default:
+				// records queued before the marker are already taken
+				close(oldest.flushed)
+			}
+		default:
+		}
+	}
+}
+
+func (a *asyncEmitter) isRunning() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.running
+}
+
+func (a *asyncEmitter) run() {
+	defer close(a.done)
+	for item := range a.queue {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+
+		item.l.emitTrace(item.rec)
+	}
+}
+
+// Backpressure sets the handling of a full queue of the asynchronous emission.
+// Dropped records are counted in Stats and reported by a warning logged
+// synchronously at most once per ReportInterval and on Close.
+func (l *GormLogger) Backpressure(cfg BackpressureConfig) {
+	if cfg.ReportInterval <= 0 {
+		cfg.ReportInterval = 10 * time.Second
+	}
+
+	if l.async == nil {
+		l.async = &asyncEmitter{}
+	}
+
+	l.async.mu.Lock()
+	defer l.async.mu.Unlock()
+	l.async.cfg = cfg
+}
+
+// reportDropped logs a warning about records dropped since the last report,
+// if the report interval has passed since it or force is set.
+func (l *GormLogger) reportDropped(force bool) {
+	a := l.async
+	a.reportMu.Lock()
+	now := l.clock.Now()
+	dropped := l.stats.droppedEvents.Load()
+	if dropped == a.reported || !force && !a.lastReport.IsZero() && now.Sub(a.lastReport) < a.cfg.ReportInterval {
+		a.reportMu.Unlock()
+		return
+	}
+
+	n := dropped - a.reported
+	a.reported, a.lastReport = dropped, now
+	a.reportMu.Unlock()
+	l.log(context.Background(), logger.Warn, "%s", strings.ReplaceAll(l.literals.DroppedEvents, "{count}", strconv.FormatUint(n, 10)))
+}
+
+// Start switches the logger to asynchronous emission: Trace puts records into
+// a queue of the given size and a background goroutine encodes and emits them.
+// Records from a single goroutine are emitted in the order they were traced.
+// Copies of the logger share the queue and emit their records with their own
+// configuration.
+// Before Start and after Close records are emitted synchronously.
+func (l *GormLogger) Start(queueSize int) {
+	if l.async == nil {
+		l.async = &asyncEmitter{}
+	}
+
+	a := l.async
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running {
+		return
+	}
+
+	a.queue = make(chan asyncItem, queueSize)
+	a.done = make(chan struct{})
+	a.running = true
+	go a.run()
+}
+
+// Flush waits until all records queued before the call are emitted or ctx is done.
+func (l *GormLogger) Flush(ctx context.Context) error {
+	if l.async == nil {
+		return nil
+	}
+
+	a := l.async
+	flushed := make(chan struct{})
+	a.mu.RLock()
+	if !a.running {
+		a.mu.RUnlock()
+		return nil
+	}
+
+	select {
+	case a.queue <- asyncItem{flushed: flushed}:
+		a.mu.RUnlock()
+	case <-ctx.Done():
+		a.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the background goroutine after draining the queue and logs
+// the number of dropped records not reported yet and the slowest statements
+// report if CollectSlowest is enabled. Records traced
+// after Close are emitted synchronously. Use Flush with a deadline before
+// Close to bound the shutdown time.
+func (l *GormLogger) Close() error {
+	defer l.reportSlowestOnClose()
+	if l.async == nil {
+		return nil
+	}
+
+	a := l.async
+	a.mu.Lock()
+	if !a.running {
+		a.mu.Unlock()
+		return nil
+	}
+
+	a.running = false
+	close(a.queue)
+	a.mu.Unlock()
+	<-a.done
+	l.reportDropped(true)
+	return nil
+}
@@ -0,0 +1,249 @@
+package gormzerolog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLoggerAsync(t *testing.T) {
+	trace := func(l *GormLogger, sql string) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	t.Run("drain on close", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory)
+		l.Start(4)
+		expected := make([]string, 0, 100)
+		for i := 0; i < 100; i++ {
+			sql := fmt.Sprintf("SELECT %d", i)
+			expected = append(expected, sql)
+			trace(l, sql)
+		}
+
+		assert.NoError(l.Close())
+		msgs := sink.messages()
+		if assert.Len(msgs, len(expected)) {
+			for i, msg := range msgs {
+				assert.Contains(msg, expected[i])
+			}
+		}
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory)
+		l.Start(16)
+		defer l.Close()
+		for i := 0; i < 10; i++ {
+			trace(l, "SELECT 1")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assert.NoError(l.Flush(ctx))
+		assert.Len(sink.messages(), 10)
+	})
+
+	t.Run("flush deadline", func(t *testing.T) {
+		assert := assert.New(t)
+		block := make(chan struct{})
+		l := NewGormLogger().WithInfo(func() Event {
+			<-block
+			return &testingEvent{}
+		})
+		l.Start(1)
+		trace(l, "SELECT 1")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		assert.ErrorIs(l.Flush(ctx), context.DeadlineExceeded)
+		close(block)
+		assert.NoError(l.Close())
+	})
+
+	t.Run("copies", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		base := NewGormLogger().WithInfo(sink.factory)
+		base.SetLogLevel(logger.Warn)
+		base.Start(4)
+		trace(base.LogMode(logger.Info).(*GormLogger), "SELECT 1")
+		trace(base.WithFields(map[string]string{"module": "orders"}).LogMode(logger.Info).(*GormLogger), "SELECT 2")
+		trace(base, "SELECT 3")
+		assert.NoError(base.Close())
+		if assert.Len(sink.events, 2) {
+			assert.Contains(sink.events[0].msg, "SELECT 1")
+			assert.NotContains(sink.events[0].added, "module")
+			assert.Contains(sink.events[1].msg, "SELECT 2")
+			assert.Equal("orders", sink.events[1].added["module"])
+		}
+	})
+
+	t.Run("sync fallback", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory)
+		assert.NoError(l.Flush(context.Background()))
+		assert.NoError(l.Close())
+		trace(l, "SELECT 1")
+		assert.Len(sink.messages(), 1)
+
+		l.Start(4)
+		assert.NoError(l.Close())
+		trace(l, "SELECT 2")
+		msgs := sink.messages()
+		if assert.Len(msgs, 2) {
+			assert.Contains(msgs[1], "SELECT 2")
+		}
+
+		assert.NoError(l.Flush(context.Background()))
+		assert.NoError(l.Close())
+	})
+}
+
+func TestGormLoggerBackpressure(t *testing.T) {
+	// newLogger returns a logger with a queue of two records and a consumer
+	// blocked on the first record until release is called.
+	newLogger := func(t *testing.T, policy BackpressurePolicy) (l *GormLogger, sink, warnSink *testingSink, release func()) {
+		sink, warnSink = &testingSink{}, &testingSink{}
+		taken := make(chan struct{})
+		block := make(chan struct{})
+		var once sync.Once
+		l = NewGormLogger().WithWarn(warnSink.factory).WithInfo(func() Event {
+			once.Do(func() {
+				close(taken)
+				<-block
+			})
+
+			return sink.factory()
+		})
+		l.Backpressure(BackpressureConfig{Policy: policy, ReportInterval: time.Hour})
+		l.Start(2)
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		<-taken
+		return l, sink, warnSink, func() { close(block) }
+	}
+
+	trace := func(l *GormLogger, from, to int) {
+		for i := from; i <= to; i++ {
+			l.Trace(context.Background(), time.Now(), func() (string, int64) { return fmt.Sprintf("SELECT %d", i), 1 }, nil)
+		}
+	}
+
+	assertEmitted := func(t *testing.T, sink *testingSink, expected ...string) {
+		msgs := sink.messages()
+		if assert.Len(t, msgs, len(expected)) {
+			for i, msg := range msgs {
+				assert.True(t, strings.HasSuffix(msg, expected[i]), "%s expected, got %s", expected[i], msg)
+			}
+		}
+	}
+
+	t.Run("block", func(t *testing.T) {
+		l, sink, warnSink, release := newLogger(t, Block)
+		trace(l, 2, 3)
+		done := make(chan struct{})
+		go func() {
+			trace(l, 4, 4)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("trace should block while the queue is full")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		release()
+		<-done
+		require.NoError(t, l.Close())
+		assertEmitted(t, sink, "SELECT 1", "SELECT 2", "SELECT 3", "SELECT 4")
+		assert.Zero(t, l.Stats().DroppedEvents)
+		assert.Empty(t, warnSink.events)
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		l, sink, warnSink, release := newLogger(t, DropNewest)
+		trace(l, 2, 5)
+		assert.Equal(t, uint64(2), l.Stats().DroppedEvents)
+		assert.Equal(t, []string{"dropped 1 trace events"}, warnSink.messages())
+
+		release()
+		require.NoError(t, l.Close())
+		assertEmitted(t, sink, "SELECT 1", "SELECT 2", "SELECT 3")
+		assert.Equal(t, []string{"dropped 1 trace events", "dropped 1 trace events"}, warnSink.messages())
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		l, sink, warnSink, release := newLogger(t, DropOldest)
+		trace(l, 2, 6)
+		assert.Equal(t, uint64(3), l.Stats().DroppedEvents)
+
+		release()
+		require.NoError(t, l.Close())
+		assertEmitted(t, sink, "SELECT 1", "SELECT 5", "SELECT 6")
+		assert.Equal(t, []string{"dropped 1 trace events", "dropped 2 trace events"}, warnSink.messages())
+	})
+
+	t.Run("drop oldest keeps flush markers", func(t *testing.T) {
+		l, sink, _, release := newLogger(t, DropOldest)
+		trace(l, 2, 2)
+		flushed := make(chan error)
+		go func() {
+			flushed <- l.Flush(context.Background())
+		}()
+
+		require.Eventually(t, func() bool { return len(l.async.queue) == 2 }, time.Second, time.Millisecond)
+		// SELECT 3 replaces SELECT 2, then SELECT 4 is dropped to keep the marker
+		trace(l, 3, 4)
+		release()
+		require.NoError(t, <-flushed)
+		assertEmitted(t, sink, "SELECT 1", "SELECT 3")
+		assert.Equal(t, uint64(2), l.Stats().DroppedEvents)
+		require.NoError(t, l.Close())
+	})
+}
+
+func BenchmarkGormLoggerTrace(b *testing.B) {
+	fc := func() (string, int64) { return "SELECT * FROM users WHERE id = 1", 1 }
+	newLogger := func() *GormLogger {
+		return NewGormLogger().WithInfo(func() Event { return &testingEvent{} })
+	}
+
+	b.Run("sync", func(b *testing.B) {
+		l := newLogger()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Trace(context.Background(), time.Now(), fc, nil)
+		}
+	})
+
+	b.Run("events disabled", func(b *testing.B) {
+		l := newLogger()
+		l.DisableEvents(true)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Trace(context.Background(), time.Now(), fc, nil)
+		}
+	})
+
+	b.Run("async", func(b *testing.B) {
+		l := newLogger()
+		l.Start(1024)
+		defer l.Close()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Trace(context.Background(), time.Now(), fc, nil)
+		}
+	})
+}
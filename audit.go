@@ -0,0 +1,132 @@
+package gormzerolog
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// AuditPrimaryKeys enables logging of primary keys of the model instances
+// created, updated or deleted by a statement as the pk field. At most limit
+// keys are logged, pk_truncated is set if there are more. Instances with zero
+// keys are skipped, keys of composite primary keys are logged as objects.
+// Zero limit disables it. The keys are collected only if the logger is
+// attached with the Plugin.
+func (l *GormLogger) AuditPrimaryKeys(limit int) {
+	l.auditPKLimit = limit
+}
+
+// auditChannel duplicates write statements to a separate event builder.
+type auditChannel struct {
+	event func() Event
+	actor func(ctx context.Context) string
+}
+
+// WithAudit returns a copy of the logger duplicating executed write
+// statements (INSERT, UPDATE, DELETE and DDL) to events of the audit event
+// builder with the actor, table, operation, rows and normalized sql fields,
+// e.g. to keep a write trail separate from debug logs. The actor is resolved
+// from the context of the statement with the actor function, which may be
+// nil. Audit events are written regardless of the log level, sampling and
+// filters. Nil event disables the audit channel.
+func (l *GormLogger) WithAudit(event func() Event, actor func(ctx context.Context) string) *GormLogger {
+	c := l.clone()
+	c.audit = nil
+	if event != nil {
+		c.audit = &auditChannel{event: event, actor: actor}
+	}
+
+	return c
+}
+
+// writeAudit writes the audit event of the record if it's a write statement.
+func (l *GormLogger) writeAudit(ctx context.Context, rec *TraceRecord) {
+	if rec.DryRun || !rec.DDL && !isWrite(rec.SQL) {
+		return
+	}
+
+	event := l.audit.event()
+	if e, ok := event.(interface{ Enabled() bool }); event == nil || ok && !e.Enabled() {
+		return
+	}
+
+	var actor string
+	if l.audit.actor != nil {
+		actor = l.audit.actor(ctx)
+	}
+
+	event = event.Str("actor", actor).
+		Str(l.fieldNames.Table, rec.Table).
+		Str(l.fieldNames.Operation, rec.Operation).
+		Any("rows", rec.Rows).
+		Str(l.fieldNames.SQL, normalizeSQL(rec.SQL))
+	if rec.StatementID != "" {
+		event = event.Str(l.fieldNames.StatementID, rec.StatementID)
+	}
+
+	if rec.Err != nil {
+		event = event.Any("error", rec.Err)
+	}
+
+	event.Msgf("%s", l.literals.Audit)
+}
+
+// primaryKeys returns the primary keys of the statement model instances.
+func primaryKeys(stmt *gorm.Statement, limit int) (keys []any, truncated bool) {
+	if stmt.Schema == nil || len(stmt.Schema.PrimaryFields) == 0 || !stmt.ReflectValue.IsValid() {
+		return nil, false
+	}
+
+	add := func(v reflect.Value) bool {
+		v = reflect.Indirect(v)
+		if v.Kind() != reflect.Struct {
+			return true
+		}
+
+		key, ok := primaryKey(stmt, v)
+		if !ok {
+			return true
+		}
+
+		if len(keys) == limit {
+			truncated = true
+			return false
+		}
+
+		keys = append(keys, key)
+		return true
+	}
+
+	switch rv := stmt.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if !add(rv.Index(i)) {
+				break
+			}
+		}
+	default:
+		add(rv)
+	}
+
+	return keys, truncated
+}
+
+// primaryKey returns the primary key of the model instance, or false if it is zero.
+func primaryKey(stmt *gorm.Statement, v reflect.Value) (any, bool) {
+	fields := stmt.Schema.PrimaryFields
+	if len(fields) == 1 {
+		value, zero := fields[0].ValueOf(stmt.Context, v)
+		return value, !zero
+	}
+
+	key := make(map[string]any, len(fields))
+	allZero := true
+	for _, f := range fields {
+		value, zero := f.ValueOf(stmt.Context, v)
+		key[f.DBName] = value
+		allZero = allZero && zero
+	}
+
+	return key, !allZero
+}
@@ -0,0 +1,48 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+type actorKey struct{}
+
+func TestGormLoggerWithAudit(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	audit := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithError(sink.factory).WithAudit(audit.factory, func(ctx context.Context) string {
+		actor, _ := ctx.Value(actorKey{}).(string)
+		return actor
+	})
+	l.SetLogLevel(logger.Silent)
+	ctx := context.WithValue(context.Background(), actorKey{}, "alice")
+	trace := func(sql string, rows int64, err error) {
+		l.Trace(ctx, time.Now(), func() (string, int64) { return sql, rows }, err)
+	}
+
+	trace("SELECT * FROM users WHERE id = 1", 1, nil)
+	trace("UPDATE users SET name = 'bob' WHERE id = 1", 1, nil)
+	trace("DELETE FROM orders WHERE id = 2", 0, errors.New("locked"))
+	trace("CREATE TABLE audits (id integer)", 0, nil)
+	assert.Empty(sink.events)
+	require.Len(t, audit.events, 3)
+
+	e := audit.events[0]
+	assert.Equal("write statement", e.msg)
+	assert.Equal(map[string]string{
+		"actor":     "alice",
+		"table":     "users",
+		"operation": "update",
+		"sql":       "UPDATE users SET name = ? WHERE id = ?",
+	}, e.added)
+	assert.Equal(int64(1), e.fields["rows"])
+	assert.Equal("locked", audit.events[1].fields["error"].(error).Error())
+	assert.Equal("ddl", audit.events[2].added["operation"])
+}
@@ -0,0 +1,52 @@
+package gormzerolog
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// b3Key is the context key of the B3 span IDs.
+type b3Key struct{}
+
+// b3IDs are the IDs of a span propagated with B3 headers.
+type b3IDs struct {
+	traceID string
+	spanID  string
+}
+
+// ContextWithB3 returns a copy of the context carrying the trace and span IDs
+// of the B3 propagation headers, the single b3 header or the X-B3-TraceId and
+// X-B3-SpanId headers, to be logged by the logger returned by WithB3. The
+// context is returned unchanged if the headers carry no IDs.
+func ContextWithB3(ctx context.Context, h http.Header) context.Context {
+	ids := b3IDs{traceID: h.Get("X-B3-TraceId"), spanID: h.Get("X-B3-SpanId")}
+	if b3 := h.Get("b3"); b3 != "" {
+		parts := strings.Split(b3, "-")
+		if len(parts) >= 2 {
+			ids = b3IDs{traceID: parts[0], spanID: parts[1]}
+		}
+	}
+
+	if ids.traceID == "" || ids.spanID == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, b3Key{}, ids)
+}
+
+// WithB3 returns a copy of the logger adding the traceId and spanId fields,
+// named like in Zipkin instrumented services, to every event of a context
+// returned by ContextWithB3.
+func (l *GormLogger) WithB3() *GormLogger {
+	return l.WithContextExtractor(b3Fields)
+}
+
+func b3Fields(ctx context.Context) map[string]any {
+	ids, ok := ctx.Value(b3Key{}).(b3IDs)
+	if !ok {
+		return nil
+	}
+
+	return map[string]any{"traceId": ids.traceID, "spanId": ids.spanID}
+}
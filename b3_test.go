@@ -0,0 +1,37 @@
+package gormzerolog
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormLoggerWithB3(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithB3()
+	for _, h := range []http.Header{
+		{"B3": {"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90"}},
+		{"X-B3-Traceid": {"80f198ee56343ba864fe8b2a57d3eff7"}, "X-B3-Spanid": {"e457b5a2e4d86bd1"}, "X-B3-Sampled": {"1"}},
+		{"B3": {"0"}},
+		{},
+	} {
+		ctx := ContextWithB3(context.Background(), h)
+		l.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	require.Len(t, sink.events, 4)
+	for _, e := range sink.events[:2] {
+		assert.Equal("80f198ee56343ba864fe8b2a57d3eff7", e.fields["traceId"])
+		assert.Equal("e457b5a2e4d86bd1", e.fields["spanId"])
+	}
+
+	for _, e := range sink.events[2:] {
+		assert.NotContains(e.fields, "traceId")
+		assert.NotContains(e.fields, "spanId")
+	}
+}
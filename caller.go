@@ -0,0 +1,380 @@
+package gormzerolog
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm/utils"
+)
+
+var (
+	gormSourceDir string
+	sourceDir     string
+	// moduleVersionRe matches version suffixes of module cache directories.
+	moduleVersionRe = regexp.MustCompile(`@v[^/]*`)
+)
+
+// fileWithLineNum return the file name and line number of the caller,
+// and the caller function name if it is enabled
+func (l *GormLogger) fileWithLineNum() (caller string, function string) {
+	if l.disableCaller {
+		return "", ""
+	}
+
+	frame, ok := callerFrame(l.callerStart, l.callerMax, l.skipCallerPackages)
+	if !ok {
+		return "", ""
+	}
+
+	if l.callerFunc {
+		function = shortFuncName(frame.Function)
+	}
+
+	return l.formatFrame(frame), function
+}
+
+// formatFrame formats the frame with the caller marshaler and path options.
+func (l *GormLogger) formatFrame(frame runtime.Frame) string {
+	if l.zerologCaller {
+		return zerolog.CallerMarshalFunc(frame.PC, frame.File, frame.Line)
+	}
+
+	if l.callerMarshaler != nil {
+		return l.callerMarshaler(frame.File, frame.Line, frame.Function)
+	}
+
+	file := frame.File
+	if l.trimCallerModule {
+		file = trimModulePath(frame, buildModulePaths())
+	}
+
+	if l.callerPathElements > 0 {
+		file = lastPathElements(file, l.callerPathElements)
+	}
+
+	return file + ":" + strconv.FormatInt(int64(frame.Line), 10)
+}
+
+// callStack returns up to depth frames outside of gorm, this package and the
+// skipped packages, counting callStack as the frame 0 and Trace as the frame 1.
+func (l *GormLogger) callStack(depth int) []string {
+	max := l.callerMax + depth
+	if max <= l.callerStart {
+		return nil
+	}
+
+	pcs := make([]uintptr, max-l.callerStart)
+	// skip runtime.Callers
+	n := runtime.Callers(l.callerStart+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	stack := make([]string, 0, depth)
+	for n > 0 && len(stack) < depth {
+		frame, more := frames.Next()
+		if !skipFrame(frame, l.skipCallerPackages) && !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, l.formatFrame(frame))
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return stack
+}
+
+// shortFuncName trims the package path from the fully qualified function
+// name, so github.com/acme/app/svc.(*User).Save becomes svc.(*User).Save.
+func shortFuncName(function string) string {
+	return function[strings.LastIndexByte(function, '/')+1:]
+}
+
+// callerFrame returns the first frame outside of gorm, this package and the
+// packages with the given prefixes among the frames from start to max, counting
+// fileWithLineNum as the frame 0 and Trace as the frame 1. If there is no such
+// frame, the deepest non-runtime frame is returned.
+func callerFrame(start, max int, skipPrefixes []string) (runtime.Frame, bool) {
+	if max <= start {
+		return runtime.Frame{}, false
+	}
+
+	var buf [32]uintptr
+	pcs := buf[:]
+	if max-start > len(buf) {
+		pcs = make([]uintptr, max-start)
+	}
+
+	// skip runtime.Callers and callerFrame
+	n := runtime.Callers(start+2, pcs[:max-start])
+	frames := runtime.CallersFrames(pcs[:n])
+	var deepest runtime.Frame
+	found := false
+	for n > 0 {
+		frame, more := frames.Next()
+		if !skipFrame(frame, skipPrefixes) {
+			return frame, true
+		}
+
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			deepest, found = frame, true
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return deepest, found
+}
+
+func skipFrame(frame runtime.Frame, skipPrefixes []string) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return matchesAnyPrefix(frame, skipPrefixes)
+	}
+
+	return strings.HasPrefix(frame.File, gormSourceDir) ||
+		path.Dir(frame.File)+"/" == sourceDir ||
+		matchesAnyPrefix(frame, skipPrefixes)
+}
+
+// matchesAnyPrefix reports whether the frame belongs to one of the prefixes,
+// which may be either file path prefixes or package import path prefixes.
+// Version suffixes of module cache directories are ignored, so the prefix
+// github.com/acme/app/repo matches the file
+// /go/pkg/mod/github.com/acme/app@v1.2.3/repo/repo.go.
+func matchesAnyPrefix(frame runtime.Frame, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return false
+	}
+
+	file := moduleVersionRe.ReplaceAllString(filepath.ToSlash(frame.File), "")
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(filepath.ToSlash(prefix), "/")
+		if prefix == "" {
+			continue
+		}
+
+		if hasPathPrefix(file, prefix) || hasPathPrefix(frame.Function, prefix) {
+			return true
+		}
+
+		if i := strings.Index(file, "/"+prefix); i >= 0 && hasPathPrefix(file[i+1:], prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasPathPrefix reports whether s starts with prefix ending at a path
+// element or a package name boundary.
+func hasPathPrefix(s, prefix string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+
+	if len(s) == len(prefix) || strings.HasSuffix(prefix, ".go") {
+		return true
+	}
+
+	switch s[len(prefix)] {
+	case '/', '.':
+		return true
+	default:
+		return false
+	}
+}
+
+// SkipCallerPackages sets prefixes of files or packages which are skipped
+// while looking for the caller, in addition to gorm and this package.
+// Prefixes may be absolute file paths or import paths like
+// github.com/acme/app/pkg/repository.
+func (l *GormLogger) SkipCallerPackages(prefixes ...string) {
+	l.skipCallerPackages = prefixes
+}
+
+// CallerResolver returns the caller of the statement traced with the context.
+type CallerResolver func(ctx context.Context) string
+
+// WithCallerResolver returns a copy of the logger with a resolver used by
+// Trace instead of the built-in stack based caller lookup, e.g. to read the
+// logical call site stored in the context. Nil resolver restores the built-in
+// lookup.
+func (l *GormLogger) WithCallerResolver(resolver CallerResolver) *GormLogger {
+	c := l.clone()
+	c.callerResolver = resolver
+	return c
+}
+
+// WithCallerMarshaler returns a copy of the logger with a function formatting
+// the caller instead of the default file:line format. It receives the absolute
+// file path and the fully qualified function name, and the path shortening
+// options are not applied.
+func (l *GormLogger) WithCallerMarshaler(marshaler func(file string, line int, fn string) string) *GormLogger {
+	c := l.clone()
+	c.callerMarshaler = marshaler
+	return c
+}
+
+// DisableCaller sets a flag for disabling the caller lookup,
+// which is omitted from trace messages then.
+func (l *GormLogger) DisableCaller(b bool) {
+	l.disableCaller = b
+}
+
+// CallStack enables capturing of up to depth application frames leading to
+// statements which failed or were slow, logged as the call_stack field of
+// their error and slow query events. Frames of gorm, this package and
+// packages set with SkipCallerPackages are skipped, and the frames are
+// formatted like the caller. The stack is captured only for such statements,
+// so other traces don't pay for it. Zero depth disables the capture.
+func (l *GormLogger) CallStack(depth int) {
+	l.stackDepth = max(depth, 0)
+}
+
+// ZerologCaller sets a flag for logging the caller in the zerolog.CallerFieldName
+// field formatted with zerolog.CallerMarshalFunc, like zerolog Caller does,
+// instead of in the message. The caller is looked up the same way, so wrapper
+// frames are skipped and asynchronous emission reports the right frame, and it
+// is added to messages logged with Info, Warn and Error too. The marshaler set
+// with WithCallerMarshaler and the path options are not applied then.
+func (l *GormLogger) ZerologCaller(b bool) {
+	l.zerologCaller = b
+}
+
+// CallerField sets a flag for logging the caller of traces in the caller field
+// instead of in the message, so log processors can group by call site. Unlike
+// with ZerologCaller, the caller is formatted like in the message.
+func (l *GormLogger) CallerField(b bool) {
+	l.callerField = b
+}
+
+// CallerFunc sets a flag for logging the caller function name
+// as the caller_func field.
+func (l *GormLogger) CallerFunc(b bool) {
+	l.callerFunc = b
+}
+
+// CallerSearch sets the range of stack frames searched for the caller, where
+// the frame 0 is the caller lookup, the frame 1 is Trace and the frame 2 is the
+// code calling Trace. Frames from start up to but not including max are checked,
+// 2 and 15 by default. If all of them belong to gorm or skipped packages, the
+// deepest non-runtime frame is reported.
+func (l *GormLogger) CallerSearch(start, max int) {
+	l.callerStart = start
+	l.callerMax = max
+}
+
+// CallerPathElements sets a number of trailing path elements kept in caller
+// file paths. Zero keeps the whole path.
+func (l *GormLogger) CallerPathElements(n int) {
+	l.callerPathElements = n
+}
+
+// TrimCallerModulePath sets a flag for stripping caller file paths up to and
+// including the module path, so /go/pkg/mod/github.com/acme/app@v1.2.3/svc/user.go
+// becomes svc/user.go.
+func (l *GormLogger) TrimCallerModulePath(b bool) {
+	l.trimCallerModule = b
+}
+
+func lastPathElements(file string, n int) string {
+	file = filepath.ToSlash(file)
+	i := len(file)
+	for ; n > 0 && i > 0; n-- {
+		i = strings.LastIndexByte(file[:i], '/')
+		if i < 0 {
+			return file
+		}
+	}
+
+	return file[i+1:]
+}
+
+// trimModulePath returns the frame file path relative to the root of its module.
+// The package import path is taken from the function name, so the result is the
+// same for module cache, vendored and -trimpath builds.
+func trimModulePath(frame runtime.Frame, modules []string) string {
+	file := filepath.ToSlash(frame.File)
+	if pkg := funcPackagePath(frame.Function); pkg != "" {
+		module := ""
+		for _, m := range modules {
+			if len(m) > len(module) && hasPathPrefix(pkg, m) {
+				module = m
+			}
+		}
+
+		if module != "" {
+			rel := strings.TrimPrefix(strings.TrimPrefix(pkg, module), "/")
+			return path.Join(rel, path.Base(file))
+		}
+	}
+
+	// fall back to the module cache and vendor directory layouts
+	if loc := moduleVersionRe.FindStringIndex(file); loc != nil {
+		return strings.TrimPrefix(file[loc[1]:], "/")
+	}
+
+	if i := strings.LastIndex(file, "/vendor/"); i >= 0 {
+		return file[i+len("/vendor/"):]
+	}
+
+	return file
+}
+
+// funcPackagePath returns the package import path of the fully qualified
+// function name like github.com/acme/app/svc.(*User).Save.
+func funcPackagePath(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[slash+1:], '.')
+	if dot < 0 {
+		return ""
+	}
+
+	return function[:slash+1+dot]
+}
+
+var (
+	modulePaths     []string
+	modulePathsOnce sync.Once
+)
+
+// buildModulePaths returns paths of the main module and its dependencies.
+func buildModulePaths() []string {
+	modulePathsOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+
+		modulePaths = append(modulePaths, info.Main.Path)
+		for _, dep := range info.Deps {
+			modulePaths = append(modulePaths, dep.Path)
+		}
+	})
+
+	return modulePaths
+}
+
+func init() {
+	// compatible solution to get gorm source directory with various operating systems
+	pc := reflect.ValueOf(utils.FileWithLineNum).Pointer()
+	file, _ := runtime.FuncForPC(pc).FileLine(pc)
+	dir := filepath.Dir(filepath.Dir(file))
+	gormSourceDir = filepath.Dir(dir)
+	if filepath.Base(gormSourceDir) != "gorm.io" {
+		gormSourceDir = dir
+	}
+
+	gormSourceDir = filepath.ToSlash(gormSourceDir) + "/"
+	_, file, _, _ = runtime.Caller(0)
+	sourceDir = filepath.ToSlash(filepath.Dir(file)) + "/"
+}
@@ -0,0 +1,436 @@
+package gormzerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormLoggerSkipCallerPackages(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: l})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Item{}))
+	repo := &testingRepository{db: db}
+
+	sink.events = nil
+	require.NoError(t, repo.find(&[]Item{}))
+	require.Len(t, sink.events, 1)
+	assert.Contains(sink.events[0].msg, "wrapper_test.go:")
+
+	_, file, _, _ := runtime.Caller(0)
+	l.SkipCallerPackages(strings.TrimSuffix(file, "caller_test.go") + "wrapper_test.go")
+	sink.events = nil
+	_, _, line, _ := runtime.Caller(0)
+	require.NoError(t, repo.find(&[]Item{}))
+	require.Len(t, sink.events, 1)
+	assert.Contains(sink.events[0].msg, file+":"+strconv.Itoa(line+1))
+}
+
+func TestMatchesAnyPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		file     string
+		function string
+		prefix   string
+		expected bool
+	}{
+		{"/go/pkg/mod/github.com/acme/app@v1.2.3/pkg/repository/repo.go", "", "github.com/acme/app/pkg/repository", true},
+		{"/go/pkg/mod/github.com/acme/app@v1.2.3-0.20230101-abcdef/pkg/repository/repo.go", "", "github.com/acme/app/pkg/repository/", true},
+		{"/go/pkg/mod/github.com/acme/app@v1.2.3/pkg/repositoryx/repo.go", "", "github.com/acme/app/pkg/repository", false},
+		{"/home/ci/app/pkg/repository/repo.go", "", "/home/ci/app/pkg/repository", true},
+		{"/home/ci/app/pkg/service/user.go", "", "/home/ci/app/pkg/repository", false},
+		{"/home/ci/app/pkg/repository/repo.go", "github.com/acme/app/pkg/repository.(*Repo).Find", "github.com/acme/app/pkg/repository", true},
+		{"/home/ci/app/pkg/service/user.go", "github.com/acme/app/pkg/service.Create", "github.com/acme/app/pkg/repository", false},
+		{"github.com/acme/app@v1.2.3/pkg/repository/repo.go", "", "github.com/acme/app/pkg/repository", true},
+	} {
+		frame := runtime.Frame{File: tt.file, Function: tt.function}
+		assert.Equal(t, tt.expected, matchesAnyPrefix(frame, []string{tt.prefix}), tt.file)
+	}
+}
+
+func TestTrimCallerPath(t *testing.T) {
+	modules := []string{"github.com/acme/app", "github.com/acme/app/tools", "gorm.io/gorm"}
+	for _, tt := range []struct {
+		file     string
+		function string
+		module   string
+		last2    string
+	}{
+		{
+			file:     "/home/ci/go/pkg/mod/github.com/acme/app@v1.2.3/internal/svc/user.go",
+			function: "github.com/acme/app/internal/svc.(*UserService).Create",
+			module:   "internal/svc/user.go",
+			last2:    "svc/user.go",
+		},
+		{
+			file:     "/home/ci/src/app/internal/svc/user.go",
+			function: "github.com/acme/app/internal/svc.Create.func1",
+			module:   "internal/svc/user.go",
+			last2:    "svc/user.go",
+		},
+		{
+			file:     "/home/ci/src/app/main.go",
+			function: "github.com/acme/app.Run",
+			module:   "main.go",
+			last2:    "app/main.go",
+		},
+		{
+			file:     "/home/ci/src/app/tools/gen/gen.go",
+			function: "github.com/acme/app/tools/gen.Generate",
+			module:   "gen/gen.go",
+			last2:    "gen/gen.go",
+		},
+		{
+			file:     "/home/ci/src/svc/vendor/github.com/acme/lib/db/db.go",
+			function: "github.com/acme/lib/db.Query",
+			module:   "github.com/acme/lib/db/db.go",
+			last2:    "db/db.go",
+		},
+		{
+			file:     "github.com/acme/lib@v0.1.0/db/db.go",
+			function: "github.com/acme/lib/db.Query",
+			module:   "db/db.go",
+			last2:    "db/db.go",
+		},
+		{
+			file:     "/home/ci/src/cmd/main.go",
+			function: "main.main",
+			module:   "/home/ci/src/cmd/main.go",
+			last2:    "cmd/main.go",
+		},
+		{
+			file:     "main.go",
+			function: "",
+			module:   "main.go",
+			last2:    "main.go",
+		},
+	} {
+		frame := runtime.Frame{File: tt.file, Function: tt.function}
+		module := trimModulePath(frame, modules)
+		assert.Equal(t, tt.module, module, tt.file)
+		assert.Equal(t, tt.last2, lastPathElements(tt.file, 2), tt.file)
+	}
+}
+
+func TestGormLoggerTrimCallerPath(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory)
+	l.TrimCallerModulePath(true)
+	_, _, line, _ := runtime.Caller(0)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	l.TrimCallerModulePath(false)
+	l.CallerPathElements(1)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	require.Len(t, sink.events, 2)
+	assert.True(strings.HasPrefix(sink.events[0].msg, "caller_test.go:"+strconv.Itoa(line+1)+"\n"), sink.events[0].msg)
+	assert.True(strings.HasPrefix(sink.events[1].msg, "caller_test.go:"+strconv.Itoa(line+4)+"\n"), sink.events[1].msg)
+}
+
+func TestGormLoggerCallerSearch(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory)
+	_, file, _, _ := runtime.Caller(0)
+	wrapper := strings.TrimSuffix(file, "caller_test.go") + "wrapper_test.go"
+	l.SkipCallerPackages(wrapper)
+
+	deepTrace(l, 20)
+	require.Len(t, sink.events, 1)
+	assert.True(strings.HasPrefix(sink.events[0].msg, wrapper+":"), "deepest frame expected, got %s", sink.events[0].msg)
+
+	l.CallerSearch(2, 64)
+	_, _, line, _ := runtime.Caller(0)
+	deepTrace(l, 20)
+	require.Len(t, sink.events, 2)
+	assert.True(strings.HasPrefix(sink.events[1].msg, file+":"+strconv.Itoa(line+1)+"\n"), sink.events[1].msg)
+
+	l.CallerSearch(3, 64)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	require.Len(t, sink.events, 3)
+	assert.Contains(sink.events[2].msg, "testing.go:", "the test function frame should be skipped")
+
+	l.CallerSearch(2, 2)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	require.Len(t, sink.events, 4)
+	assert.True(strings.HasPrefix(sink.events[3].msg, "["), sink.events[3].msg)
+}
+
+func TestGormLoggerDisableCaller(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	l.DisableCaller(true)
+	l.SlowThreshold(time.Millisecond)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("failed"))
+	l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 2", 1 }, nil)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 3", -1 }, nil)
+
+	require.Len(t, errorSink.events, 1)
+	assert.Regexp(`^failed\n\[\d+\.\d{3}ms\] \[rows:1\] SELECT 1$`, errorSink.events[0].msg)
+	require.Len(t, warnSink.events, 1)
+	assert.Regexp(`^SLOW SQL >= 1ms\n\[\d+\.\d{3}ms\] \[rows:1\] SELECT 2$`, warnSink.events[0].msg)
+	require.Len(t, infoSink.events, 3)
+	assert.Regexp(`^\[\d+\.\d{3}ms\] \[rows:-\] SELECT 3$`, infoSink.events[2].msg)
+	for _, e := range append(append(infoSink.events, warnSink.events...), errorSink.events...) {
+		assert.NotContains(e.msg, ".go:")
+	}
+}
+
+func BenchmarkGormLoggerCaller(b *testing.B) {
+	fc := func() (string, int64) { return "SELECT * FROM users WHERE id = 1", 1 }
+	for _, disabled := range []bool{false, true} {
+		b.Run("disabled="+strconv.FormatBool(disabled), func(b *testing.B) {
+			l := NewGormLogger().WithInfo(func() Event { return &testingEvent{} })
+			l.DisableCaller(disabled)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l.Trace(context.Background(), time.Now(), fc, nil)
+			}
+		})
+	}
+}
+
+func TestGormLoggerCallerFunc(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: l})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Item{}))
+	repo := &testingRepository{db: db}
+
+	sink.events = nil
+	require.NoError(t, repo.find(&[]Item{}))
+	require.Len(t, sink.events, 1)
+	assert.NotContains(sink.events[0].added, "caller_func")
+
+	l.CallerFunc(true)
+	require.NoError(t, repo.find(&[]Item{}))
+	func() {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}()
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	require.Len(t, sink.events, 4)
+	assert.Equal("gorm-zerolog.(*testingRepository).query", sink.events[1].added["caller_func"])
+	assert.Equal("gorm-zerolog.TestGormLoggerCallerFunc.func1", sink.events[2].added["caller_func"])
+	assert.Equal("gorm-zerolog.TestGormLoggerCallerFunc", sink.events[3].added["caller_func"])
+}
+
+func TestGormLoggerCallerMarshaler(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory)
+	_, file, line, _ := runtime.Caller(0)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	require.Len(t, sink.events, 1)
+	assert.True(strings.HasPrefix(sink.events[0].msg, file+":"+strconv.Itoa(line+1)+"\n"), sink.events[0].msg)
+
+	var marshaled []any
+	l = l.WithCallerMarshaler(func(file string, line int, fn string) string {
+		marshaled = []any{file, line, fn}
+		return "https://code.example.com/" + path.Base(file) + "#L" + strconv.Itoa(line)
+	})
+	l.CallerPathElements(1)
+	_, _, line, _ = runtime.Caller(0)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	require.Len(t, sink.events, 2)
+	assert.Equal([]any{file, line + 1, "github.com/vitaliy-art/gorm-zerolog.TestGormLoggerCallerMarshaler"}, marshaled)
+	assert.True(strings.HasPrefix(sink.events[1].msg, "https://code.example.com/caller_test.go#L"+strconv.Itoa(line+1)+"\n"), sink.events[1].msg)
+}
+
+func TestGormLoggerCallerResolver(t *testing.T) {
+	type callSiteKey struct{}
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithCallerResolver(func(ctx context.Context) string {
+		site, _ := ctx.Value(callSiteKey{}).(string)
+		return site
+	})
+
+	ctx := context.WithValue(context.Background(), callSiteKey{}, "orders.Checkout")
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 2", 1 }, nil)
+	l = l.WithCallerResolver(nil)
+	_, file, _, _ := runtime.Caller(0)
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 3", 1 }, nil)
+	require.Len(t, sink.events, 3)
+	assert.True(strings.HasPrefix(sink.events[0].msg, "orders.Checkout\n"), sink.events[0].msg)
+	assert.True(strings.HasPrefix(sink.events[1].msg, "["), sink.events[1].msg)
+	assert.True(strings.HasPrefix(sink.events[2].msg, file+":"), sink.events[2].msg)
+}
+
+func TestGormLoggerCallStack(t *testing.T) {
+	type Item struct {
+		ID uint
+	}
+
+	type Missing struct {
+		ID uint
+	}
+
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	l.CallStack(3)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: l})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Item{}))
+	repo := &testingRepository{db: db}
+	_, file, _, _ := runtime.Caller(0)
+	wrapper := strings.TrimSuffix(file, "caller_test.go") + "wrapper_test.go"
+
+	infoSink.events = nil
+	require.NoError(t, repo.find(&[]Item{}))
+	require.Len(t, infoSink.events, 1)
+	assert.NotContains(infoSink.events[0].fields, "call_stack")
+
+	_, _, line, _ := runtime.Caller(0)
+	require.Error(t, repo.find(&[]Missing{}))
+	require.Len(t, errorSink.events, 1)
+	stack, _ := errorSink.events[0].fields["call_stack"].([]string)
+	require.Len(t, stack, 3)
+	assert.True(strings.HasPrefix(stack[0], wrapper+":"), stack[0])
+	assert.True(strings.HasPrefix(stack[1], wrapper+":"), stack[1])
+	assert.Equal(file+":"+strconv.Itoa(line+1), stack[2])
+	assert.NotContains(infoSink.events[1].fields, "call_stack")
+
+	l.SkipCallerPackages(wrapper)
+	l.SlowThreshold(time.Nanosecond)
+	_, _, line, _ = runtime.Caller(0)
+	require.NoError(t, repo.find(&[]Item{}))
+	require.Len(t, warnSink.events, 1)
+	stack, _ = warnSink.events[0].fields["call_stack"].([]string)
+	require.Len(t, stack, 2, "test function and testing.tRunner expected, got %v", stack)
+	assert.Equal(file+":"+strconv.Itoa(line+1), stack[0])
+	assert.Contains(stack[1], "testing.go:")
+}
+
+func TestGormLoggerCallStackDirectTrace(t *testing.T) {
+	assert := assert.New(t)
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithError(errorSink.factory)
+	l.CallStack(2)
+	_, file, line, _ := runtime.Caller(0)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 0 }, errors.New("test"))
+	require.Len(t, errorSink.events, 1)
+	stack, _ := errorSink.events[0].fields["call_stack"].([]string)
+	require.Len(t, stack, 2)
+	assert.Equal(file+":"+strconv.Itoa(line+1), stack[0])
+	assert.Contains(stack[1], "testing.go:")
+}
+
+func TestGormLoggerCallerField(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithError(sink.factory)
+	l.CallerField(true)
+	l.CallerPathElements(1)
+	_, _, line, _ := runtime.Caller(0)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("test"))
+	require.Len(t, sink.events, 2)
+	for _, e := range sink.events {
+		assert.Equal("caller_test.go:"+strconv.Itoa(line+1), e.added["caller"])
+		assert.NotContains(e.msg, "caller_test.go")
+	}
+
+	assert.True(strings.HasPrefix(sink.events[0].msg, "test\n"), sink.events[0].msg)
+
+	l = l.WithCallerResolver(func(context.Context) string { return "handler:42" })
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	assert.Equal("handler:42", sink.events[2].added["caller"])
+
+	l.CallerField(false)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	assert.NotContains(sink.events[3].added, "caller")
+	assert.True(strings.HasPrefix(sink.events[3].msg, "handler:42\n"))
+}
+
+func TestGormLoggerZerologCaller(t *testing.T) {
+	marshal := zerolog.CallerMarshalFunc
+	zerolog.CallerMarshalFunc = func(_ uintptr, file string, line int) string {
+		return "custom:" + path.Base(file) + ":" + strconv.Itoa(line)
+	}
+	defer func() { zerolog.CallerMarshalFunc = marshal }()
+
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithWarn(sink.factory).WithError(sink.factory)
+	l.ZerologCaller(true)
+	here := func(line int) string {
+		return "custom:caller_test.go:" + strconv.Itoa(line)
+	}
+
+	_, _, line, _ := runtime.Caller(0)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 2", 1 }, nil)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 3", 1 }, errors.New("test"))
+	l.Info(context.Background(), "migrated")
+	require.Len(t, sink.events, 6)
+	for i, expected := range []int{1, 2, 2, 3, 3, 4} {
+		e := sink.events[i]
+		assert.Equal(here(line+expected), e.added[zerolog.CallerFieldName], e.msg)
+		assert.NotContains(e.msg, "caller_test.go", e.msg)
+	}
+
+	t.Run("async", func(t *testing.T) {
+		sink.events = nil
+		l.Start(4)
+		_, _, line, _ := runtime.Caller(0)
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		require.NoError(t, l.Close())
+		require.Len(t, sink.events, 1)
+		assert.Equal(here(line+1), sink.events[0].added[zerolog.CallerFieldName])
+	})
+
+	t.Run("wrappers", func(t *testing.T) {
+		type Item struct {
+			ID uint
+		}
+
+		buf := &bytes.Buffer{}
+		zl := zerolog.New(buf)
+		l := NewGormLogger()
+		l.base.logger.Store(&zl)
+		l.ZerologCaller(true)
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: l})
+		require.NoError(t, err)
+		require.NoError(t, db.AutoMigrate(&Item{}))
+		_, file, _, _ := runtime.Caller(0)
+		l.SkipCallerPackages(strings.TrimSuffix(file, "caller_test.go") + "wrapper_test.go")
+
+		buf.Reset()
+		_, _, line, _ := runtime.Caller(0)
+		require.NoError(t, (&testingRepository{db: db}).find(&[]Item{}))
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(here(line+1), entry[zerolog.CallerFieldName])
+	})
+}
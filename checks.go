@@ -0,0 +1,272 @@
+package gormzerolog
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm/logger"
+)
+
+// WarnLargeInLists enables a check of IN (...) lists of statements: traces of
+// statements with a list of more than limit elements are logged at warn level
+// with large_in_list and in_list_size fields. Zero limit disables the check.
+func (l *GormLogger) WarnLargeInLists(limit int) {
+	l.inListLimit = limit
+}
+
+// FlagUnboundedSelects enables a check of SELECT statements on the given
+// tables: traces of statements without a LIMIT clause, which don't consist of
+// aggregates only, get the unbounded_select field and are logged at least at
+// the given level. Use logger.Info to only flag them. Table names are matched
+// case-insensitively, with or without a schema. No tables disable the check.
+func (l *GormLogger) FlagUnboundedSelects(logLevel logger.LogLevel, tables ...string) {
+	l.unboundedLevel = logLevel
+	l.unboundedTables = nil
+	if len(tables) == 0 {
+		return
+	}
+
+	l.unboundedTables = make(map[string]struct{}, len(tables))
+	for _, table := range tables {
+		l.unboundedTables[strings.ToLower(table)] = struct{}{}
+	}
+}
+
+// FlagMissingDeadlines enables a check of contexts of statements: traces of
+// statements executed with a context without a deadline get the no_deadline
+// field and are logged at least at the given level. Statements matching any
+// of the allow patterns and statements executed with a context returned by
+// WithoutDeadline are not flagged. logger.Silent disables the check.
+func (l *GormLogger) FlagMissingDeadlines(logLevel logger.LogLevel, allow ...*regexp.Regexp) {
+	l.deadlineLevel = logLevel
+	l.deadlineAllowed = allow
+}
+
+// FlagNoRowsAffected enables a check of UPDATE and DELETE statements: traces
+// of statements which succeeded without affecting any rows, which often means
+// an optimistic locking conflict or a stale ID, get the no_rows_affected field
+// and are logged at least at the given level. Use logger.Info to only flag
+// them. Statements with an unknown number of rows are not flagged.
+// logger.Silent disables the check.
+func (l *GormLogger) FlagNoRowsAffected(logLevel logger.LogLevel) {
+	l.noRowsLevel = logLevel
+}
+
+// updatesRows reports whether the operation updates or deletes rows.
+func updatesRows(operation string) bool {
+	return operation == "update" || operation == "delete"
+}
+
+// RowsAffectedWarnThreshold enables a check of write statements: traces of
+// statements which affected more than n rows get the rows_affected_excessive
+// field and are logged at least at warn level, or the level set with
+// RowsAffectedLevel, however fast they are. Reads and statements with
+// an unknown number of rows are not checked. Zero n disables the check,
+// except for tables with their own threshold.
+func (l *GormLogger) RowsAffectedWarnThreshold(n int64) {
+	l.rowsThreshold = n
+}
+
+// RowsAffectedThresholdFor sets a threshold of the RowsAffectedWarnThreshold
+// check for statements on the table, which takes precedence over the common
+// one. The table name is matched case-insensitively, with or without
+// a schema. Zero n removes the threshold of the table.
+func (l *GormLogger) RowsAffectedThresholdFor(table string, n int64) {
+	table = strings.ToLower(table)
+	if n <= 0 {
+		delete(l.tableRowsThresholds, table)
+		return
+	}
+
+	if l.tableRowsThresholds == nil {
+		l.tableRowsThresholds = make(map[string]int64)
+	}
+
+	l.tableRowsThresholds[table] = n
+}
+
+// RowsAffectedLevel sets a log level for traces of statements exceeding
+// the RowsAffectedWarnThreshold, which is warn by default.
+func (l *GormLogger) RowsAffectedLevel(logLevel logger.LogLevel) {
+	l.rowsLevel = logLevel
+}
+
+// rowsAffectedThreshold returns the threshold of the statement on the table,
+// zero if the check is disabled.
+func (l *GormLogger) rowsAffectedThreshold(table string) int64 {
+	if n, ok := tableValue(l.tableRowsThresholds, table); ok {
+		return n
+	}
+
+	return l.rowsThreshold
+}
+
+type withoutDeadlineKey struct{}
+
+// WithoutDeadline returns a context which opts statements executed with it
+// out of the FlagMissingDeadlines check, e.g. for migrations.
+func WithoutDeadline(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutDeadlineKey{}, true)
+}
+
+func (l *GormLogger) missesDeadline(ctx context.Context, sql string) bool {
+	if ctx != nil {
+		if _, ok := ctx.Deadline(); ok || ctx.Value(withoutDeadlineKey{}) != nil {
+			return false
+		}
+	}
+
+	for _, re := range l.deadlineAllowed {
+		if re.MatchString(sql) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// statementMatcher matches statements by a prefix or a regular expression.
+type statementMatcher struct {
+	prefix string
+	re     *regexp.Regexp
+}
+
+func (m statementMatcher) match(sql string) bool {
+	if m.re != nil {
+		return m.re.MatchString(sql)
+	}
+
+	sql = strings.Join(strings.Fields(skipSQLNoise(sql)), " ")
+	return len(sql) >= len(m.prefix) && strings.EqualFold(sql[:len(m.prefix)], m.prefix)
+}
+
+// EscalateStatements makes traces of statements matching any of the patterns
+// logged as a single error event with the dangerous_statement field, even if
+// they succeeded quickly. Such events bypass samplers, SampleErrors and the
+// overload protection. A pattern enclosed in slashes, like /^ALTER TABLE
+// "?payments/i, is a regular expression, otherwise it is a case-insensitive
+// prefix of the statement with whitespace collapsed, like "DROP TABLE".
+// An error is returned if a regular expression is malformed, leaving the
+// current patterns untouched. No patterns disable the escalation.
+func (l *GormLogger) EscalateStatements(patterns ...string) error {
+	matchers := make([]statementMatcher, 0, len(patterns))
+	for _, pattern := range patterns {
+		expr, ok := strings.CutPrefix(pattern, "/")
+		if !ok || !strings.Contains(expr, "/") {
+			matchers = append(matchers, statementMatcher{prefix: strings.Join(strings.Fields(pattern), " ")})
+			continue
+		}
+
+		i := strings.LastIndexByte(expr, '/')
+		expr, flags := expr[:i], expr[i+1:]
+		if flags != "" {
+			expr = "(?" + flags + ")" + expr
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid statement pattern %q: %w", pattern, err)
+		}
+
+		matchers = append(matchers, statementMatcher{re: re})
+	}
+
+	l.escalated = matchers
+	return nil
+}
+
+func (l *GormLogger) isDangerous(sql string) bool {
+	for _, m := range l.escalated {
+		if m.match(sql) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check runs the enabled checks of the statement, escalating the record.
+func (l *GormLogger) check(ctx context.Context, rec *TraceRecord) {
+	if len(l.escalated) > 0 && l.isDangerous(rec.SQL) {
+		rec.DangerousStatement = true
+		rec.escalate(logger.Error)
+	}
+
+	if l.inListLimit > 0 {
+		if size := maxInListSize(rec.SQL); size > l.inListLimit {
+			rec.InListSize = size
+			rec.escalate(logger.Warn)
+		}
+	}
+
+	if l.unboundedTables != nil && l.watchesTable(rec.Table) && isUnboundedSelect(rec.SQL) {
+		rec.UnboundedSelect = true
+		rec.escalate(l.unboundedLevel)
+	}
+
+	if l.deadlineLevel > logger.Silent && l.missesDeadline(ctx, rec.SQL) {
+		rec.NoDeadline = true
+		rec.escalate(l.deadlineLevel)
+	}
+
+	if l.noRowsLevel > logger.Silent && rec.Rows == 0 && rec.Err == nil && updatesRows(rec.Operation) {
+		rec.NoRowsAffected = true
+		rec.escalate(l.noRowsLevel)
+	}
+
+	if l.rowsThreshold > 0 || len(l.tableRowsThresholds) > 0 {
+		if n := l.rowsAffectedThreshold(rec.Table); n > 0 && rec.Rows > n && isWrite(rec.SQL) {
+			rec.RowsAffectedExcessive = true
+			rec.escalate(l.rowsLevel)
+		}
+	}
+
+	l.checkRegression(rec)
+}
+
+func (l *GormLogger) watchesTable(table string) bool {
+	return containsTable(l.unboundedTables, table)
+}
+
+// containsTable reports whether the table, with or without a schema, is in
+// the set of lower case table names.
+func containsTable(tables map[string]struct{}, table string) bool {
+	_, ok := tableValue(tables, table)
+	return ok
+}
+
+// tableValue returns the value of the table, with or without a schema,
+// from the map keyed by lower case table names.
+func tableValue[V any](tables map[string]V, table string) (V, bool) {
+	if table == "" {
+		var zero V
+		return zero, false
+	}
+
+	table = strings.ToLower(table)
+	if v, ok := tables[table]; ok {
+		return v, true
+	}
+
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		v, ok := tables[table[i+1:]]
+		return v, ok
+	}
+
+	var zero V
+	return zero, false
+}
+
+// escalate raises the level the record is logged at, if it is more severe.
+// logger.Silent never wins over the levels of other checks.
+func (r *TraceRecord) escalate(logLevel logger.LogLevel) {
+	if logLevel <= logger.Silent {
+		return
+	}
+
+	if r.level == 0 || logLevel < r.level {
+		r.level = logLevel
+	}
+}
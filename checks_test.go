@@ -0,0 +1,345 @@
+package gormzerolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLoggerWarnLargeInLists(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	trace := func(sql string, err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, err)
+	}
+
+	trace("SELECT * FROM users WHERE id IN (1,2,3,4)", nil)
+	assert.Len(infoSink.events, 1)
+	assert.Empty(warnSink.events)
+
+	l.WarnLargeInLists(3)
+	trace("SELECT * FROM users WHERE id IN (1,2,3)", nil)
+	assert.Len(infoSink.events, 2)
+	assert.NotContains(infoSink.events[1].fields, "large_in_list")
+
+	trace("SELECT * FROM users WHERE id IN (1,2,3,4)", nil)
+	assert.Len(infoSink.events, 2)
+	require.Len(t, warnSink.events, 1)
+	assert.Equal(true, warnSink.events[0].fields["large_in_list"])
+	assert.Equal(4, warnSink.events[0].fields["in_list_size"])
+
+	trace("SELECT * FROM users WHERE id IN (1,2,3,4)", errors.New("test"))
+	require.Len(t, errorSink.events, 1)
+	assert.Equal(4, errorSink.events[0].fields["in_list_size"])
+	assert.Len(warnSink.events, 2)
+}
+
+func TestGormLoggerFlagUnboundedSelects(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory)
+	trace := func(sql string) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	l.FlagUnboundedSelects(logger.Info, "Events")
+	trace("SELECT * FROM `events`")
+	trace("SELECT * FROM public.events WHERE kind = 'a'")
+	trace("SELECT * FROM events LIMIT 10")
+	trace("SELECT count(*) FROM events")
+	trace("SELECT * FROM users")
+	require.Len(t, infoSink.events, 5)
+	for i, flagged := range []bool{true, true, false, false, false} {
+		if flagged {
+			assert.Equal(true, infoSink.events[i].fields["unbounded_select"], i)
+		} else {
+			assert.NotContains(infoSink.events[i].fields, "unbounded_select", i)
+		}
+	}
+
+	assert.Empty(warnSink.events)
+
+	l.FlagUnboundedSelects(logger.Warn, "events")
+	trace("SELECT * FROM events WHERE id IN (SELECT event_id FROM marks LIMIT 1)")
+	trace("SELECT * FROM users")
+	require.Len(t, warnSink.events, 1)
+	assert.Equal(true, warnSink.events[0].fields["unbounded_select"])
+	assert.Len(infoSink.events, 6)
+
+	l.FlagUnboundedSelects(logger.Warn)
+	trace("SELECT * FROM events")
+	assert.Len(warnSink.events, 1)
+}
+
+func TestGormLoggerChecksWhileOverloaded(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithClock(clock)
+	l.SlowThreshold(0)
+	l.OverloadProtection(OverloadConfig{MaxRate: 10, RecoverRate: 5, Sustain: time.Second})
+	trace := func(sql string) {
+		clock.Advance(time.Second / 20)
+		l.Trace(context.Background(), clock.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	for i := 0; i < 40; i++ {
+		trace("SELECT 1")
+	}
+
+	require.True(t, l.Stats().Overloaded)
+	infos, warns := len(infoSink.events), len(warnSink.events)
+
+	l.FlagUnboundedSelects(logger.Info, "events")
+	trace("SELECT * FROM events")
+	assert.Len(infoSink.events, infos, "checks at the info level should not escalate")
+
+	l.FlagUnboundedSelects(logger.Warn, "events")
+	trace("SELECT * FROM events")
+	assert.Len(warnSink.events, warns+1)
+}
+
+func TestGormLoggerFlagNoRowsAffected(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	trace := func(sql string, rows int64, err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, rows }, err)
+	}
+
+	l.FlagNoRowsAffected(logger.Info)
+	trace("UPDATE users SET name = 'a' WHERE id = 1 AND version = 2", 0, nil)
+	trace("UPDATE users SET name = 'a' WHERE id = 1", 1, nil)
+	trace("UPDATE users SET name = 'a'", -1, nil)
+	trace("DELETE FROM users WHERE id = 1", 0, nil)
+	trace("SELECT * FROM users WHERE id = 1", 0, nil)
+	require.Len(t, infoSink.events, 5)
+	for i, flagged := range []bool{true, false, false, true, false} {
+		if flagged {
+			assert.Equal(true, infoSink.events[i].fields["no_rows_affected"], i)
+		} else {
+			assert.NotContains(infoSink.events[i].fields, "no_rows_affected", i)
+		}
+	}
+
+	assert.Empty(warnSink.events)
+
+	l.FlagNoRowsAffected(logger.Warn)
+	trace("DELETE FROM users WHERE id = 1", 0, nil)
+	trace("UPDATE users SET name = 'a' WHERE id = 1", 0, errors.New("locked"))
+	require.Len(t, warnSink.events, 1)
+	assert.Equal(true, warnSink.events[0].fields["no_rows_affected"])
+	require.Len(t, errorSink.events, 1)
+	assert.NotContains(errorSink.events[0].fields, "no_rows_affected")
+
+	l.FlagNoRowsAffected(logger.Silent)
+	trace("DELETE FROM users WHERE id = 1", 0, nil)
+	assert.Len(warnSink.events, 1)
+}
+
+func TestGormLoggerRowsAffectedWarnThreshold(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	excessive := func(sql string, rows int64) bool {
+		warns := len(warnSink.events)
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, rows }, nil)
+		if len(warnSink.events) == warns {
+			return false
+		}
+
+		assert.Equal(true, warnSink.events[warns].fields["rows_affected_excessive"], sql)
+		return true
+	}
+
+	l.RowsAffectedWarnThreshold(100)
+	for _, sql := range []string{"UPDATE users SET active = 0", "DELETE FROM users"} {
+		assert.False(excessive(sql, 99), sql)
+		assert.False(excessive(sql, 100), sql)
+		assert.True(excessive(sql, 101), sql)
+		assert.False(excessive(sql, -1), sql)
+	}
+
+	assert.False(excessive("SELECT * FROM users", 1000))
+	assert.NotContains(infoSink.events[len(infoSink.events)-1].fields, "rows_affected_excessive")
+
+	t.Run("tables", func(t *testing.T) {
+		l.RowsAffectedThresholdFor("Events", 10000)
+		l.RowsAffectedThresholdFor("users", 10)
+		assert.False(excessive("DELETE FROM events", 5000))
+		assert.True(excessive("DELETE FROM public.events", 10001))
+		assert.True(excessive("UPDATE users SET active = 0", 11))
+		assert.True(excessive("DELETE FROM orders", 101))
+
+		l.RowsAffectedWarnThreshold(0)
+		assert.False(excessive("DELETE FROM orders", 101))
+		assert.True(excessive("DELETE FROM events", 10001))
+
+		l.RowsAffectedThresholdFor("events", 0)
+		assert.False(excessive("DELETE FROM events", 10001))
+		l.RowsAffectedThresholdFor("users", 0)
+	})
+
+	t.Run("level", func(t *testing.T) {
+		l.RowsAffectedWarnThreshold(100)
+		l.RowsAffectedLevel(logger.Error)
+		assert.False(excessive("DELETE FROM users", 101))
+		require.Len(t, errorSink.events, 1)
+		assert.Equal(true, errorSink.events[0].fields["rows_affected_excessive"])
+	})
+}
+
+func TestGormLoggerFlagMissingDeadlines(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory)
+	trace := func(ctx context.Context, sql string) {
+		l.Trace(ctx, time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	deadline, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	trace(context.Background(), "SELECT * FROM users")
+	require.Len(t, infoSink.events, 1)
+	assert.NotContains(infoSink.events[0].fields, "no_deadline")
+
+	l.FlagMissingDeadlines(logger.Warn, regexp.MustCompile(`(?i)^SELECT .* FROM schema_migrations`))
+	trace(deadline, "SELECT * FROM users")
+	trace(context.Background(), "SELECT * FROM users")
+	trace(context.Background(), "SELECT * FROM schema_migrations")
+	trace(WithoutDeadline(context.Background()), "CREATE TABLE users (id int)")
+	trace(WithoutDeadline(deadline), "SELECT * FROM users")
+	require.Len(t, warnSink.events, 1)
+	assert.Equal(true, warnSink.events[0].fields["no_deadline"])
+	require.Len(t, infoSink.events, 5)
+	for _, e := range infoSink.events {
+		assert.NotContains(e.fields, "no_deadline")
+	}
+
+	l.FlagMissingDeadlines(logger.Info)
+	trace(context.Background(), "SELECT * FROM schema_migrations")
+	require.Len(t, infoSink.events, 6)
+	assert.Equal(true, infoSink.events[5].fields["no_deadline"])
+
+	l.FlagMissingDeadlines(logger.Silent)
+	trace(context.Background(), "SELECT * FROM users")
+	assert.NotContains(infoSink.events[6].fields, "no_deadline")
+	assert.Len(warnSink.events, 1)
+}
+
+func TestGormLoggerEscalateStatements(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithError(errorSink.factory)
+	require.NoError(t, l.EscalateStatements("drop  table", "TRUNCATE", `/^ALTER TABLE "?payments/i`))
+	trace := func(sql string, err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 0 }, err)
+	}
+
+	for _, sql := range []string{
+		"DROP TABLE users",
+		"/* migration */ DROP\n  TABLE IF EXISTS users",
+		"truncate sessions",
+		`alter table "payments" ADD COLUMN note text`,
+	} {
+		trace(sql, nil)
+	}
+
+	require.Len(t, errorSink.events, 4)
+	assert.Empty(infoSink.events)
+	for _, e := range errorSink.events {
+		assert.Equal(true, e.fields["dangerous_statement"])
+	}
+
+	trace("DELETE FROM users WHERE id = 1", nil)
+	trace("ALTER TABLE users ADD COLUMN note text", nil)
+	trace("SELECT * FROM drop_tables", nil)
+	assert.Len(errorSink.events, 4)
+	require.Len(t, infoSink.events, 3)
+	assert.NotContains(infoSink.events[0].fields, "dangerous_statement")
+
+	trace("DROP TABLE users", errors.New("no such table: users"))
+	require.Len(t, errorSink.events, 5, "a failed statement should be logged once")
+	assert.Contains(errorSink.events[4].msg, "no such table: users")
+	assert.Len(infoSink.events, 3)
+
+	assert.Error(l.EscalateStatements("/(/"))
+	trace("DROP TABLE users", nil)
+	assert.Len(errorSink.events, 6, "patterns should be kept on error")
+
+	require.NoError(t, l.EscalateStatements())
+	trace("DROP TABLE users", nil)
+	assert.Len(errorSink.events, 6)
+	assert.Len(infoSink.events, 4)
+}
+
+func TestGormLoggerEscalateStatementsBypass(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	zl := zerolog.New(buf)
+	clock := &testingClock{now: time.Now()}
+	l := NewGormLogger().WithClock(clock).WithLevelSampler(logger.Error, &zerolog.BasicSampler{N: 1000})
+	l.base.logger.Store(&zl)
+	l.OverloadProtection(OverloadConfig{MaxRate: 1, Window: time.Second})
+	l.SampleErrors(ErrorSampling{Every: 1000})
+	require.NoError(t, l.EscalateStatements("DROP TABLE"))
+	for i := 0; i < 30; i++ {
+		l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "DROP TABLE users", 0 }, nil)
+		l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "DROP TABLE users", 0 }, errors.New("locked"))
+		clock.Advance(100 * time.Millisecond)
+	}
+
+	assert.True(l.Stats().Overloaded)
+	assert.Equal(60, strings.Count(buf.String(), `"dangerous_statement":true`))
+	assert.Less(strings.Count(buf.String(), "SELECT 1"), 30, "successful traces should be suppressed")
+}
+
+func TestGormLoggerSilentChecks(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	l.FlagUnboundedSelects(logger.Silent, "users")
+	l.WarnLargeInLists(2)
+	trace := func(sql string) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	trace("SELECT * FROM users WHERE id IN (1,2,3)")
+	require.Len(t, warnSink.events, 1, "silent checks should not lower the level of other checks")
+	assert.Equal(true, warnSink.events[0].fields["unbounded_select"])
+	assert.Empty(infoSink.events)
+
+	require.NoError(t, l.EscalateStatements("SELECT * FROM users"))
+	trace("SELECT * FROM users")
+	require.Len(t, errorSink.events, 1)
+	assert.Equal(true, errorSink.events[0].fields["dangerous_statement"])
+	assert.Empty(infoSink.events)
+
+	require.NoError(t, l.EscalateStatements())
+	trace("SELECT * FROM users")
+	require.Len(t, infoSink.events, 1)
+	assert.Equal(true, infoSink.events[0].fields["unbounded_select"])
+}
@@ -0,0 +1,50 @@
+package gormzerolog
+
+import (
+	"time"
+)
+
+// Clock is a source of the current time used by the logger.
+type Clock interface {
+	Now() time.Time
+}
+
+// Timer is a timer started by a TimerClock.
+type Timer interface {
+	// Stop prevents the timer from firing. It reports whether the timer was
+	// stopped before it fired.
+	Stop() bool
+}
+
+// TimerClock is a Clock which also runs timers, e.g. a manually driven clock
+// firing them when advanced. Real timers are used with other clocks.
+type TimerClock interface {
+	Clock
+	// AfterFunc calls f in its own goroutine or the one advancing the clock
+	// after d has elapsed.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// WithClock returns a copy of the logger with a clock used for all its time
+// reads, e.g. elapsed time calculation. The real time is used by default.
+func (l *GormLogger) WithClock(clock Clock) *GormLogger {
+	c := l.clone()
+	c.clock = clock
+	return c
+}
+
+// afterFunc starts a timer of the clock, or a real one if the clock doesn't
+// run timers.
+func (l *GormLogger) afterFunc(d time.Duration, f func()) Timer {
+	if c, ok := l.clock.(TimerClock); ok {
+		return c.AfterFunc(d, f)
+	}
+
+	return time.AfterFunc(d, f)
+}
@@ -0,0 +1,57 @@
+package gormzerolog
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testingClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestGormLoggerSlowThresholdWithClock(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithClock(clock)
+	l.SlowThreshold(time.Millisecond * 200)
+	trace := func(elapsed time.Duration) {
+		begin := clock.Now()
+		clock.Advance(elapsed)
+		l.Trace(context.Background(), begin, func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	trace(time.Millisecond * 200)
+	assert.Empty(warnSink.events)
+	assert.Len(infoSink.events, 1)
+	assert.Contains(infoSink.events[0].msg, "[200.000ms]")
+
+	trace(time.Millisecond*200 + time.Microsecond)
+	assert.Len(warnSink.events, 1)
+	assert.Contains(warnSink.events[0].msg, "SLOW SQL >= 200ms")
+	assert.Contains(warnSink.events[0].msg, "[200.001ms]")
+	assert.Equal(true, warnSink.events[0].fields["slow"])
+	assert.Equal(200.0, warnSink.events[0].fields["slow_threshold_ms"])
+	for _, e := range infoSink.events {
+		assert.NotContains(e.fields, "slow")
+		assert.NotContains(e.fields, "slow_threshold_ms")
+	}
+}
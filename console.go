@@ -0,0 +1,23 @@
+package gormzerolog
+
+import (
+	"io"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// NewConsoleGormLogger creates a logger writing human-readable events with
+// timestamps to out using zerolog.ConsoleWriter. The SQL of traces is logged
+// on its own line.
+func NewConsoleGormLogger(out io.Writer) *GormLogger {
+	writer := zerolog.NewConsoleWriter()
+	writer.Out = out
+	writer.TimeFormat = time.DateTime
+	zl := zerolog.New(writer).With().Timestamp().Logger()
+
+	l := NewGormLogger()
+	l.base.logger.Store(&zl)
+	l.SQLPlacement(OwnLine)
+	return l
+}
@@ -0,0 +1,25 @@
+package gormzerolog
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConsoleGormLogger(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	l := NewConsoleGormLogger(buf)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT *\nFROM users", 1 }, nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(lines, 3)
+	assert.Contains(lines[0], "INF")
+	assert.Contains(lines[0], "console_test.go:")
+	assert.Contains(lines[1], "[rows:1] SELECT *")
+	assert.True(strings.HasPrefix(lines[2], "FROM users"))
+}
@@ -0,0 +1,78 @@
+package gormzerolog
+
+import (
+	"context"
+	"slices"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm/logger"
+)
+
+// ContextLogger sets a flag for writing events with the zerolog logger
+// attached to the context of the statement or message with
+// zerolog.Logger.WithContext, so request-scoped fields are kept. Events of
+// contexts without a logger are written with the configured event builders.
+// The zerolog.DefaultContextLogger is not used.
+func (l *GormLogger) ContextLogger(b bool) {
+	l.contextLoggers = b
+}
+
+// WithContextExtractor returns a copy of the logger calling the extractor
+// with the context of every statement or message and adding the returned
+// fields with their types preserved, e.g. request and user IDs. The fields
+// take precedence over fields of field providers and extractors added before.
+func (l *GormLogger) WithContextExtractor(extract func(ctx context.Context) map[string]any) *GormLogger {
+	c := l.clone()
+	c.contextExtractors = append(slices.Clip(l.contextExtractors), extract)
+	return c
+}
+
+// contextLogger returns the logger attached to the context, or nil if there
+// is none or context loggers are disabled.
+func (l *GormLogger) contextLogger(ctx context.Context) *zerolog.Logger {
+	if !l.contextLoggers || ctx == nil {
+		return nil
+	}
+
+	zl := zerolog.Ctx(ctx)
+	if zl == zerolog.Ctx(context.Background()) {
+		return nil
+	}
+
+	return zl
+}
+
+type levelKey struct{}
+
+// WithLevel returns a context which overrides the log level of statements and
+// messages executed with it, e.g. to debug a single request in production.
+// It takes precedence over tenant overrides set with SetTenantLevel.
+func WithLevel(ctx context.Context, logLevel logger.LogLevel) context.Context {
+	return context.WithValue(ctx, levelKey{}, logLevel)
+}
+
+// Silence returns a context which suppresses logging of statements and
+// messages executed with it, e.g. for health checks and cache warmers,
+// without changing the global level. The audit channel is not affected.
+func Silence(ctx context.Context) context.Context {
+	return WithLevel(ctx, logger.Silent)
+}
+
+// levelFor returns the log level of statements and messages with the context.
+func (l *GormLogger) levelFor(ctx context.Context) logger.LogLevel {
+	if ctx == nil {
+		return l.level()
+	}
+
+	if logLevel, ok := ctx.Value(levelKey{}).(logger.LogLevel); ok {
+		return logLevel
+	}
+
+	if l.tenants != nil {
+		if logLevel, ok := override(ctx, l.tenants, l.tenants.levels); ok {
+			return logLevel
+		}
+	}
+
+	return l.level()
+}
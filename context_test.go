@@ -0,0 +1,116 @@
+package gormzerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLoggerContextLogger(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithWarn(sink.factory)
+	l.AdditionalData = map[string]string{"service": "svc"}
+	ctx := zerolog.New(buf).With().Str("request_id", "r1").Logger().WithContext(context.Background())
+	trace := func(ctx context.Context) {
+		l.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	trace(ctx)
+	assert.Len(sink.events, 1, "context loggers are disabled by default")
+	assert.Empty(buf.String())
+
+	l.ContextLogger(true)
+	trace(ctx)
+	l.Warn(ctx, "warned")
+	assert.Len(sink.events, 1)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for i, level := range []string{"info", "warn"} {
+		entry := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &entry))
+		assert.Equal(level, entry["level"])
+		assert.Equal("r1", entry["request_id"])
+		assert.Equal("svc", entry["service"])
+	}
+
+	trace(context.Background())
+	assert.Len(sink.events, 2, "contexts without a logger fall back to the factories")
+}
+
+type requestIDKey struct{}
+
+func TestGormLoggerWithContextExtractor(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).
+		WithFieldProvider(func() map[string]any { return map[string]any{"request_id": "none", "locale": "en"} }).
+		WithContextExtractor(func(ctx context.Context) map[string]any {
+			id, ok := ctx.Value(requestIDKey{}).(string)
+			if !ok {
+				return nil
+			}
+
+			return map[string]any{"request_id": id, "user_id": 42}
+		})
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "r1")
+
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	l.Info(ctx, "test")
+	l.Info(context.Background(), "test")
+	require.Len(t, sink.events, 3)
+	for _, e := range sink.events[:2] {
+		assert.Equal("r1", e.fields["request_id"])
+		assert.Equal(42, e.fields["user_id"])
+		assert.Equal("en", e.fields["locale"])
+	}
+
+	assert.Equal(map[string]any{"request_id": "none", "locale": "en"}, sink.events[2].fields)
+}
+
+func TestGormLoggerWithLevel(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithDebug(sink.factory).WithInfo(sink.factory).WithError(sink.factory)
+	l.SetLogLevel(logger.Error)
+	trace := func(ctx context.Context) {
+		l.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	trace(context.Background())
+	assert.Empty(sink.events)
+
+	debug := WithLevel(context.Background(), Debug)
+	trace(debug)
+	l.Info(debug, "test")
+	assert.Len(sink.events, 2)
+
+	silent := WithLevel(context.Background(), logger.Silent)
+	l.Trace(silent, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("test"))
+	l.Error(silent, "test")
+	assert.Len(sink.events, 2)
+}
+
+func TestGormLoggerSilence(t *testing.T) {
+	sink := &testingSink{}
+	audit := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithError(sink.factory).WithAudit(audit.factory, nil)
+	ctx := Silence(context.Background())
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "UPDATE users SET name = 'a'", 1 }, errors.New("test"))
+	l.Info(ctx, "test")
+	assert.Empty(t, sink.events)
+	assert.Len(t, audit.events, 1)
+
+	l.Info(context.Background(), "test")
+	assert.Len(t, sink.events, 1)
+}
@@ -0,0 +1,146 @@
+package gormzerolog
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// sqlStateError is implemented by Postgres driver errors, like
+// *pgconn.PgError and *pq.Error.
+type sqlStateError interface {
+	SQLState() string
+}
+
+// sqlState returns the SQLSTATE code and the constraint name of a Postgres
+// driver error wrapped by err. The constraint is read from the
+// ConstraintName or Constraint field, so drivers are not imported.
+func sqlState(err error) (code, constraint string) {
+	var e sqlStateError
+	if !errors.As(err, &e) {
+		return "", ""
+	}
+
+	return e.SQLState(), stringField(e, "ConstraintName", "Constraint")
+}
+
+// stringField returns the first non-empty string field of the struct
+// v points to with one of the names.
+func stringField(v any, names ...string) string {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return ""
+	}
+
+	for _, name := range names {
+		if f := rv.FieldByName(name); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+			return f.String()
+		}
+	}
+
+	return ""
+}
+
+// mysqlClasses are human-readable classes of MySQL error numbers.
+var mysqlClasses = map[uint64]string{
+	1062: "duplicate key",
+	1205: "lock wait timeout",
+	1213: "deadlock",
+	1451: "foreign key violation",
+	1452: "foreign key violation",
+}
+
+// mysqlErrno returns the error number of a *mysql.MySQLError of the
+// go-sql-driver/mysql driver wrapped by err, or zero. The error is detected
+// by its type name and Number field, so the driver is not imported.
+func mysqlErrno(err error) uint64 {
+	var number uint64
+	findError(err, func(err error) bool {
+		rv := reflect.ValueOf(err)
+		if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct || rv.Elem().Type().Name() != "MySQLError" {
+			return false
+		}
+
+		f := rv.Elem().FieldByName("Number")
+		if !f.IsValid() || !f.CanUint() {
+			return false
+		}
+
+		number = f.Uint()
+		return true
+	})
+
+	return number
+}
+
+// findError reports whether the tree of errors wrapped by err has an error
+// matching the predicate.
+func findError(err error, match func(error) bool) bool {
+	for err != nil {
+		if match(err) {
+			return true
+		}
+
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				if findError(err, match) {
+					return true
+				}
+			}
+
+			return false
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// retryableMessages are lower case fragments of messages of deadlock and
+// serialization errors of drivers without structured errors, like SQLite
+// and SQL Server.
+var retryableMessages = []string{
+	"deadlock",
+	"could not serialize access",
+	"database is locked",
+	"database table is locked",
+}
+
+// isRetryable reports whether the record failed with a deadlock, a lock wait
+// timeout or a serialization failure, which can be retried.
+func isRetryable(rec *TraceRecord) bool {
+	switch rec.SQLState {
+	case "40001", "40P01":
+		return true
+	}
+
+	switch rec.MySQLErrno {
+	case 1205, 1213:
+		return true
+	}
+
+	msg := strings.ToLower(rec.Err.Error())
+	for _, fragment := range retryableMessages {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithRetryable returns a copy of the logger writing error traces of
+// statements failed with a deadlock, a lock wait timeout or a serialization
+// failure with the builder instead of the error builder, e.g. to keep retry
+// loop noise from paging on-call. The traces get the retryable field
+// regardless of the builder. Statements escalated with EscalateStatements are
+// still logged with the error builder. Nil builder disables the routing.
+func (l *GormLogger) WithRetryable(builder func() Event) *GormLogger {
+	c := l.clone()
+	c.retryableBuilder = builder
+	return c
+}
@@ -0,0 +1,131 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pgError mimics *pgconn.PgError.
+type pgError struct {
+	Code           string
+	ConstraintName string
+}
+
+func (e *pgError) Error() string    { return "ERROR (SQLSTATE " + e.Code + ")" }
+func (e *pgError) SQLState() string { return e.Code }
+
+// pqError mimics *pq.Error.
+type pqError struct {
+	Code       string
+	Constraint string
+}
+
+func (e *pqError) Error() string    { return "pq: error" }
+func (e *pqError) SQLState() string { return e.Code }
+
+func TestGormLoggerSQLState(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	nop := func() Event { return &testingEvent{} }
+	l := NewGormLogger().WithInfo(nop).WithError(sink.factory)
+	for _, err := range []error{
+		fmt.Errorf("create: %w", &pgError{Code: "23505", ConstraintName: "users_email_key"}),
+		&pqError{Code: "23503", Constraint: "orders_user_id_fkey"},
+		&pgError{Code: "40001"},
+		errors.New("locked"),
+	} {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "INSERT INTO users (id) VALUES (1)", 0 }, err)
+	}
+
+	require.Len(t, sink.events, 4)
+	assert.Equal("23505", sink.events[0].added["sqlstate"])
+	assert.Equal("users_email_key", sink.events[0].added["constraint"])
+	assert.Equal("23503", sink.events[1].added["sqlstate"])
+	assert.Equal("orders_user_id_fkey", sink.events[1].added["constraint"])
+	assert.Equal("40001", sink.events[2].added["sqlstate"])
+	assert.NotContains(sink.events[2].added, "constraint")
+	assert.NotContains(sink.events[3].added, "sqlstate")
+}
+
+// MySQLError mimics *mysql.MySQLError.
+type MySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (e *MySQLError) Error() string { return fmt.Sprintf("Error %d: %s", e.Number, e.Message) }
+
+func TestGormLoggerMySQLErrno(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	nop := func() Event { return &testingEvent{} }
+	l := NewGormLogger().WithInfo(nop).WithError(sink.factory)
+	for _, err := range []error{
+		fmt.Errorf("create: %w", &MySQLError{Number: 1062, Message: "Duplicate entry"}),
+		errors.Join(errors.New("tx"), &MySQLError{Number: 1213, Message: "Deadlock found"}),
+		&MySQLError{Number: 1146, Message: "Table doesn't exist"},
+		errors.New("locked"),
+	} {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "INSERT INTO users (id) VALUES (1)", 0 }, err)
+	}
+
+	require.Len(t, sink.events, 4)
+	assert.Equal(uint64(1062), sink.events[0].fields["mysql_errno"])
+	assert.Equal("duplicate key", sink.events[0].added["mysql_error_class"])
+	assert.Equal("deadlock", sink.events[1].added["mysql_error_class"])
+	assert.Equal(uint64(1146), sink.events[2].fields["mysql_errno"])
+	assert.NotContains(sink.events[2].added, "mysql_error_class")
+	assert.NotContains(sink.events[3].fields, "mysql_errno")
+}
+
+func TestGormLoggerRetryable(t *testing.T) {
+	assert := assert.New(t)
+	errorSink := &testingSink{}
+	retrySink := &testingSink{}
+	nop := func() Event { return &testingEvent{} }
+	l := NewGormLogger().WithInfo(nop).WithError(errorSink.factory)
+	trace := func(err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "UPDATE accounts SET balance = 0", 0 }, err)
+	}
+
+	retryable := []error{
+		&pgError{Code: "40001"},
+		&pgError{Code: "40P01"},
+		&MySQLError{Number: 1213},
+		&MySQLError{Number: 1205},
+		errors.New("database is locked (5) (SQLITE_BUSY)"),
+		errors.New("Transaction (Process ID 52) was deadlocked on lock resources"),
+	}
+	for _, err := range retryable {
+		trace(err)
+	}
+
+	trace(&pgError{Code: "23505"})
+	require.Len(t, errorSink.events, 7)
+	for i, e := range errorSink.events[:6] {
+		assert.Equal(true, e.fields["retryable"], i)
+	}
+	assert.NotContains(errorSink.events[6].fields, "retryable")
+
+	l = l.WithRetryable(retrySink.factory)
+	for _, err := range retryable {
+		trace(err)
+	}
+
+	trace(errors.New("locked"))
+	assert.Len(retrySink.events, 6)
+	assert.Len(errorSink.events, 8)
+
+	require.NoError(t, l.EscalateStatements("UPDATE accounts"))
+	trace(&pgError{Code: "40001"})
+	assert.Len(retrySink.events, 6, "dangerous statements should be logged with the error builder")
+	require.Len(t, errorSink.events, 9)
+	assert.Equal(true, errorSink.events[8].fields["dangerous_statement"])
+}
@@ -0,0 +1,108 @@
+package gormzerolog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestParseDSN(t *testing.T) {
+	const secret = "s3cr3t"
+	for dsn, expected := range map[string]DatabaseInfo{
+		"postgres://gorm:" + secret + "@db.example.com:5432/orders?sslmode=disable":          {Name: "orders", Host: "db.example.com:5432"},
+		"postgresql://gorm:" + secret + "@10.0.0.1/orders":                                   {Name: "orders", Host: "10.0.0.1"},
+		"host=pg.internal user=gorm password=" + secret + " dbname=orders port=5432":         {Name: "orders", Host: "pg.internal:5432"},
+		"host=pg.internal password='" + secret + "' dbname=orders":                           {Name: "orders", Host: "pg.internal"},
+		"gorm:" + secret + "@tcp(mysql.internal:3306)/orders?charset=utf8mb4&parseTime=True": {Name: "orders", Host: "mysql.internal:3306"},
+		"gorm:p@ss/" + secret + "@tcp(mysql.internal:3306)/orders":                           {Name: "orders", Host: "mysql.internal:3306"},
+		"sqlserver://gorm:" + secret + "@localhost:9930?database=orders":                     {Name: "orders", Host: "localhost:9930"},
+		"file:orders.db?cache=shared&_pragma=key(" + secret + ")":                            {Name: "orders.db"},
+		"orders.db": {Name: "orders.db"},
+		":memory:":  {Name: ":memory:"},
+		"":          {},
+		"postgres://gorm:" + secret + "@%zz/orders": {},
+	} {
+		info := parseDSN(dsn)
+		assert.Equal(t, expected, info, dsn)
+		for _, v := range []string{info.Name, info.Host, info.Role} {
+			assert.NotContains(t, v, secret, dsn)
+		}
+	}
+}
+
+func TestGormLoggerDatabaseInfo(t *testing.T) {
+	t.Run("explicit", func(t *testing.T) {
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory).WithDatabaseInfo(DatabaseInfo{Name: "orders", Host: "db:5432", Role: "replica"})
+		l.Info(context.Background(), "test")
+		require.Len(t, sink.events, 1)
+		assert.Equal(t, map[string]string{"db_name": "orders", "db_host": "db:5432", "db_role": "replica"}, sink.events[0].added)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory).WithDatabaseInfo(DatabaseInfo{Role: "primary"})
+		l.DisableBanner(true)
+		dsn := "file:" + t.TempDir() + "/orders.db?_pragma=busy_timeout(5000)"
+		db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, l.Apply(db))
+		require.NoError(t, db.Exec("SELECT 1").Error)
+		require.Len(t, sink.events, 1)
+		e := sink.events[0]
+		assert.True(strings.HasSuffix(e.added["db_name"], "/orders.db"))
+		assert.Equal("primary", e.added["db_role"])
+		assert.NotContains(e.added, "db_host")
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory)
+		l.DisableBanner(true)
+		dir := t.TempDir()
+		dbs := make([]*gorm.DB, 0, 2)
+		for _, name := range []string{"orders.db", "users.db"} {
+			db, err := gorm.Open(sqlite.Open("file:"+dir+"/"+name), &gorm.Config{})
+			require.NoError(t, err)
+			require.NoError(t, l.Apply(db))
+			dbs = append(dbs, db)
+		}
+
+		for _, db := range dbs {
+			require.NoError(t, db.Exec("SELECT 1").Error)
+		}
+
+		l.Info(context.Background(), "test")
+		require.Len(t, sink.events, 3)
+		assert.True(strings.HasSuffix(sink.events[0].added["db_name"], "/orders.db"))
+		assert.True(strings.HasSuffix(sink.events[1].added["db_name"], "/users.db"))
+		assert.NotContains(sink.events[2].added, "db_name")
+		assert.Equal(DatabaseInfo{}, l.dbInfo)
+	})
+}
+
+func TestGormLoggerDBTarget(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory)
+	l.DisableBanner(true)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+
+	ctx := context.Background()
+	require.NoError(t, db.WithContext(WithDBTarget(ctx, "replica")).Exec("SELECT 1").Error)
+	require.NoError(t, db.WithContext(WithDBTarget(ctx, "primary")).Exec("SELECT 2").Error)
+	require.NoError(t, db.WithContext(ctx).Exec("SELECT 3").Error)
+	require.Len(t, sink.events, 3)
+	assert.Equal("replica", sink.events[0].added["db_target"])
+	assert.Equal("primary", sink.events[1].added["db_target"])
+	assert.NotContains(sink.events[2].added, "db_target")
+}
@@ -0,0 +1,304 @@
+package gormzerolog
+
+import (
+	"cmp"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// debugTableLimit is the number of distinct tables collected for the debug
+// handler, further tables are collected as OtherLabel.
+const debugTableLimit = 256
+
+var defaultDebugBounds = []time.Duration{
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// errorKeyDetailRe matches key values repeated by Postgres in errors of
+// constraint violations, like Key (email)=(a@example.com).
+var errorKeyDetailRe = regexp.MustCompile(`(Key \([^)]*\))=\(.*\)`)
+
+// redactError returns the message of the error with literal values replaced
+// by placeholders like in normalized SQL.
+func redactError(err error) string {
+	return errorKeyDetailRe.ReplaceAllString(normalizeSQL(err.Error()), "$1=(?)")
+}
+
+type debugTable struct {
+	Table  string  `json:"table"`
+	Count  uint64  `json:"count"`
+	Errors uint64  `json:"errors"`
+	AvgMs  float64 `json:"avg_ms"`
+	MaxMs  float64 `json:"max_ms"`
+	total  time.Duration
+	max    time.Duration
+}
+
+type debugQuery struct {
+	Time      time.Time `json:"time"`
+	SQL       string    `json:"sql"`
+	ElapsedMs float64   `json:"elapsed_ms"`
+	Rows      int64     `json:"rows"`
+	Error     string    `json:"error,omitempty"`
+	Table     string    `json:"table,omitempty"`
+	Operation string    `json:"operation,omitempty"`
+}
+
+type debugBucket struct {
+	// LeMs is the inclusive upper bound, nil for the last bucket.
+	LeMs  *float64 `json:"le_ms"`
+	Count uint64   `json:"count"`
+}
+
+// debugCollector collects the latency histogram, per table stats and recent
+// statements shown by the debug handler.
+type debugCollector struct {
+	mu     sync.Mutex
+	bounds []time.Duration
+	counts []uint64
+	tables map[string]*debugTable
+	recent []debugQuery
+	next   int
+	full   bool
+}
+
+func newDebugCollector(recent int, bounds []time.Duration) *debugCollector {
+	return &debugCollector{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)+1),
+		tables: make(map[string]*debugTable),
+		recent: make([]debugQuery, recent),
+	}
+}
+
+func (c *debugCollector) add(rec *TraceRecord) {
+	q := debugQuery{
+		Time:      rec.Begin,
+		SQL:       normalizeSQL(rec.SQL),
+		ElapsedMs: float64(rec.Elapsed.Nanoseconds()) / 1e6,
+		Rows:      rec.Rows,
+		Table:     rec.Table,
+		Operation: rec.Operation,
+	}
+
+	if rec.Err != nil {
+		q.Error = redactError(rec.Err)
+	}
+
+	bucket, _ := slices.BinarySearch(c.bounds, rec.Elapsed)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[bucket]++
+	c.recent[c.next] = q
+	c.next = (c.next + 1) % len(c.recent)
+	c.full = c.full || c.next == 0
+
+	table := rec.Table
+	if table == "" {
+		return
+	}
+
+	t, ok := c.tables[table]
+	if !ok {
+		if len(c.tables) >= debugTableLimit {
+			table = OtherLabel
+			t = c.tables[table]
+		}
+
+		if t == nil {
+			t = &debugTable{Table: table}
+			c.tables[table] = t
+		}
+	}
+
+	t.Count++
+	t.total += rec.Elapsed
+	t.max = max(t.max, rec.Elapsed)
+	if rec.Err != nil {
+		t.Errors++
+	}
+}
+
+func (c *debugCollector) snapshot() (histogram []debugBucket, tables []debugTable, recent []debugQuery) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	histogram = make([]debugBucket, len(c.counts))
+	for i, count := range c.counts {
+		histogram[i].Count = count
+		if i < len(c.bounds) {
+			le := float64(c.bounds[i].Nanoseconds()) / 1e6
+			histogram[i].LeMs = &le
+		}
+	}
+
+	tables = make([]debugTable, 0, len(c.tables))
+	for _, t := range c.tables {
+		s := *t
+		s.AvgMs = float64((t.total / time.Duration(t.Count)).Nanoseconds()) / 1e6
+		s.MaxMs = float64(t.max.Nanoseconds()) / 1e6
+		tables = append(tables, s)
+	}
+
+	slices.SortFunc(tables, func(a, b debugTable) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Table, b.Table)
+	})
+
+	n := c.next
+	if c.full {
+		n = len(c.recent)
+	}
+
+	recent = make([]debugQuery, 0, n)
+	for i := 1; i <= n; i++ {
+		recent = append(recent, c.recent[(c.next-i+len(c.recent))%len(c.recent)])
+	}
+
+	return histogram, tables, recent
+}
+
+// CollectDebugInfo enables collection of the latency histogram, per table
+// stats and up to recent most recent statements shown by DebugHandler.
+// The histogram uses the bounds set with SetLatencyBuckets, if any,
+// otherwise 1ms, 10ms, 100ms and 1s. Zero recent disables the collection.
+func (l *GormLogger) CollectDebugInfo(recent int) {
+	if recent <= 0 {
+		l.debug = nil
+		return
+	}
+
+	bounds := defaultDebugBounds
+	if l.latencyBuckets != nil {
+		bounds = l.latencyBuckets.bounds
+	}
+
+	l.debug = newDebugCollector(recent, bounds)
+}
+
+type debugStats struct {
+	Traces              uint64 `json:"traces"`
+	Overloaded          bool   `json:"overloaded"`
+	OverloadTransitions uint64 `json:"overload_transitions"`
+	CollapsedLabels     uint64 `json:"collapsed_labels"`
+	DroppedEvents       uint64 `json:"dropped_events"`
+}
+
+type debugSlowStatement struct {
+	SQL   string  `json:"sql"`
+	Count int64   `json:"count"`
+	MaxMs float64 `json:"max_ms"`
+	AvgMs float64 `json:"avg_ms"`
+}
+
+type debugInfo struct {
+	Config           map[string]any       `json:"config"`
+	Stats            debugStats           `json:"stats"`
+	LatencyHistogram []debugBucket        `json:"latency_histogram"`
+	Tables           []debugTable         `json:"tables"`
+	Slowest          []debugSlowStatement `json:"slowest"`
+	Recent           []debugQuery         `json:"recent"`
+}
+
+func (l *GormLogger) debugInfo() debugInfo {
+	stats := l.Stats()
+	info := debugInfo{
+		Config: map[string]any{
+			"level":               LevelName(l.level()),
+			"slow_threshold":      l.threshold().String(),
+			"ddl_level":           LevelName(l.ddlLevel),
+			"sql_placement":       l.sqlPlacement.String(),
+			"async":               l.async != nil && l.async.isRunning(),
+			"tx_buffer":           l.txBufferSize,
+			"sampling":            l.sampler != nil || len(l.samplers) > 0,
+			"overload_protection": l.overload != nil,
+			"caller":              !l.disableCaller,
+		},
+		Stats: debugStats{
+			Traces:              stats.Traces,
+			Overloaded:          stats.Overloaded,
+			OverloadTransitions: stats.OverloadTransitions,
+			CollapsedLabels:     stats.CollapsedLabels,
+			DroppedEvents:       stats.DroppedEvents,
+		},
+		LatencyHistogram: []debugBucket{},
+		Tables:           []debugTable{},
+		Slowest:          []debugSlowStatement{},
+		Recent:           []debugQuery{},
+	}
+
+	if l.debug != nil {
+		info.LatencyHistogram, info.Tables, info.Recent = l.debug.snapshot()
+	}
+
+	for _, s := range l.Slowest() {
+		info.Slowest = append(info.Slowest, debugSlowStatement{
+			SQL:   s.SQL,
+			Count: s.Count,
+			MaxMs: float64(s.Max.Nanoseconds()) / 1e6,
+			AvgMs: float64(s.Avg.Nanoseconds()) / 1e6,
+		})
+	}
+
+	return info
+}
+
+var debugPage = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head><title>gorm zerolog</title></head>
+<body>
+<h1>Config</h1>
+<table>{{range $k, $v := .Config}}<tr><td>{{$k}}</td><td>{{$v}}</td></tr>{{end}}</table>
+<h1>Stats</h1>
+<table>
+<tr><td>traces</td><td>{{.Stats.Traces}}</td></tr>
+<tr><td>overloaded</td><td>{{.Stats.Overloaded}}</td></tr>
+<tr><td>overload transitions</td><td>{{.Stats.OverloadTransitions}}</td></tr>
+<tr><td>collapsed labels</td><td>{{.Stats.CollapsedLabels}}</td></tr>
+<tr><td>dropped events</td><td>{{.Stats.DroppedEvents}}</td></tr>
+</table>
+<h1>Latency</h1>
+<table>{{range .LatencyHistogram}}<tr><td>{{if .LeMs}}&le; {{.LeMs}}ms{{else}}more{{end}}</td><td>{{.Count}}</td></tr>{{end}}</table>
+<h1>Tables</h1>
+<table><tr><th>table</th><th>count</th><th>errors</th><th>avg ms</th><th>max ms</th></tr>
+{{range .Tables}}<tr><td>{{.Table}}</td><td>{{.Count}}</td><td>{{.Errors}}</td><td>{{printf "%.3f" .AvgMs}}</td><td>{{printf "%.3f" .MaxMs}}</td></tr>{{end}}</table>
+<h1>Slowest</h1>
+<table><tr><th>sql</th><th>count</th><th>avg ms</th><th>max ms</th></tr>
+{{range .Slowest}}<tr><td><code>{{.SQL}}</code></td><td>{{.Count}}</td><td>{{printf "%.3f" .AvgMs}}</td><td>{{printf "%.3f" .MaxMs}}</td></tr>{{end}}</table>
+<h1>Recent</h1>
+<table><tr><th>time</th><th>sql</th><th>ms</th><th>rows</th><th>error</th></tr>
+{{range .Recent}}<tr><td>{{.Time.Format "15:04:05.000"}}</td><td><code>{{.SQL}}</code></td><td>{{printf "%.3f" .ElapsedMs}}</td><td>{{.Rows}}</td><td>{{.Error}}</td></tr>{{end}}</table>
+</body>
+</html>
+`))
+
+// DebugHandler returns a handler serving the configuration, stats and data
+// collected with CollectDebugInfo and CollectSlowest as JSON, or as an HTML
+// page if the format=html query parameter is set or the client accepts HTML,
+// e.g. to be mounted under /debug/gorm on an internal port. SQL and errors are
+// shown normalized, with literal values replaced by placeholders. The handler
+// is safe to use while statements are traced.
+func (l *GormLogger) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := l.debugInfo()
+		if r.URL.Query().Get("format") == "html" || strings.Contains(r.Header.Get("Accept"), "text/html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = debugPage.Execute(w, info)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(info)
+	})
+}
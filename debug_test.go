@@ -0,0 +1,135 @@
+package gormzerolog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormLoggerDebugHandler(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	nop := func() Event { return &testingEvent{} }
+	l := NewGormLogger().WithInfo(nop).WithWarn(nop).WithError(nop).WithClock(clock)
+	l.CollectDebugInfo(2)
+	l.CollectSlowest(5)
+	trace := func(sql string, elapsed time.Duration, err error) {
+		l.Trace(context.Background(), clock.Now().Add(-elapsed), func() (string, int64) { return sql, 1 }, err)
+	}
+
+	trace("SELECT * FROM users WHERE email = 'a@example.com'", 500*time.Microsecond, nil)
+	trace("SELECT * FROM users WHERE email = 'b@example.com'", 5*time.Millisecond, nil)
+	trace("UPDATE orders SET total = 10 WHERE id = 1", 2*time.Second, errors.New(`ERROR: duplicate key value violates unique constraint "orders_email_key" (SQLSTATE 23505): Key (email)=(c@example.com) already exists.`))
+
+	rec := httptest.NewRecorder()
+	l.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/gorm", nil))
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("application/json", rec.Header().Get("Content-Type"))
+
+	var info struct {
+		Config           map[string]any `json:"config"`
+		Stats            map[string]any `json:"stats"`
+		LatencyHistogram []struct {
+			LeMs  *float64 `json:"le_ms"`
+			Count uint64   `json:"count"`
+		} `json:"latency_histogram"`
+		Tables  []map[string]any `json:"tables"`
+		Slowest []map[string]any `json:"slowest"`
+		Recent  []map[string]any `json:"recent"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+
+	assert.Equal("info", info.Config["level"])
+	assert.Equal("own_line", info.Config["sql_placement"])
+	assert.Equal(map[string]any{
+		"traces":               3.0,
+		"overloaded":           false,
+		"overload_transitions": 0.0,
+		"collapsed_labels":     0.0,
+		"dropped_events":       0.0,
+	}, info.Stats)
+
+	require.Len(t, info.LatencyHistogram, 5)
+	for i, count := range []uint64{1, 1, 0, 0, 1} {
+		assert.Equal(count, info.LatencyHistogram[i].Count, i)
+	}
+	assert.Equal(1.0, *info.LatencyHistogram[0].LeMs)
+	assert.Nil(info.LatencyHistogram[4].LeMs)
+
+	assert.Equal([]map[string]any{
+		{"table": "users", "count": 2.0, "errors": 0.0, "avg_ms": 2.75, "max_ms": 5.0},
+		{"table": "orders", "count": 1.0, "errors": 1.0, "avg_ms": 2000.0, "max_ms": 2000.0},
+	}, info.Tables)
+
+	require.Len(t, info.Slowest, 2)
+	assert.Equal("UPDATE orders SET total = ? WHERE id = ?", info.Slowest[0]["sql"])
+
+	require.Len(t, info.Recent, 2)
+	assert.Equal("UPDATE orders SET total = ? WHERE id = ?", info.Recent[0]["sql"])
+	assert.Equal(`ERROR: duplicate key value violates unique constraint "orders_email_key" (SQLSTATE ?): Key (email)=(?) already exists.`, info.Recent[0]["error"])
+	assert.Equal("SELECT * FROM users WHERE email = ?", info.Recent[1]["sql"])
+	assert.NotContains(rec.Body.String(), "example.com", "literals should be redacted")
+
+	rec = httptest.NewRecorder()
+	l.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/gorm?format=html", nil))
+	assert.Equal("text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(rec.Body.String(), "<code>UPDATE orders SET total = ? WHERE id = ?</code>")
+	assert.NotContains(rec.Body.String(), "example.com")
+}
+
+func TestRedactError(t *testing.T) {
+	for msg, expected := range map[string]string{
+		"locked":                                "locked",
+		"UNIQUE constraint failed: users.email": "UNIQUE constraint failed: users.email",
+		"Error 1062 (23000): Duplicate entry 'a@example.com' for key 'users.email'":                                      "Error ? (?): Duplicate entry ? for key ?",
+		`insert or update on table "orders" violates foreign key constraint "fk_user" Key (user_id)=(42) is not present`: `insert or update on table "orders" violates foreign key constraint "fk_user" Key (user_id)=(?) is not present`,
+	} {
+		assert.Equal(t, expected, redactError(errors.New(msg)), msg)
+	}
+}
+
+func TestGormLoggerDebugHandlerDisabled(t *testing.T) {
+	l := NewGormLogger()
+	rec := httptest.NewRecorder()
+	l.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	for _, key := range []string{`"latency_histogram":[]`, `"tables":[]`, `"slowest":[]`, `"recent":[]`} {
+		assert.Contains(t, body, key)
+	}
+}
+
+func TestGormLoggerDebugHandlerConcurrent(t *testing.T) {
+	nop := func() Event { return &testingEvent{} }
+	l := NewGormLogger().WithInfo(nop).WithWarn(nop).WithError(nop)
+	l.CollectDebugInfo(16)
+	l.CollectSlowest(4)
+	handler := l.DebugHandler()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT * FROM users WHERE id = 1", 1 }, nil)
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.True(t, json.Valid(rec.Body.Bytes()))
+		assert.True(t, strings.HasPrefix(rec.Body.String(), `{"config":`))
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,130 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// errorKind classifies errors matching the target with errors.Is.
+type errorKind struct {
+	target error
+	kind   string
+	level  logger.LogLevel
+}
+
+// defaultErrorKinds are the kinds of errors translated by GORM if
+// TranslateError is enabled.
+var defaultErrorKinds = []errorKind{
+	{target: gorm.ErrDuplicatedKey, kind: "duplicated_key"},
+	{target: gorm.ErrForeignKeyViolated, kind: "foreign_key_violated"},
+}
+
+// SetErrorKind classifies errors matching the target with errors.Is as the
+// kind, logged as the error_kind field of traces of failed statements and set
+// to TraceRecord.ErrorKind. The traces are logged at the given level, zero
+// keeps the default level, logger.Silent disables logging of the failures.
+// GORM's translated gorm.ErrDuplicatedKey and gorm.ErrForeignKeyViolated are
+// classified as duplicated_key and foreign_key_violated by default, other
+// errors like gorm.ErrCheckConstraintViolated of newer GORM versions can be
+// registered. An empty kind removes the classification of the target.
+func (l *GormLogger) SetErrorKind(target error, kind string, logLevel logger.LogLevel) {
+	kinds := slices.DeleteFunc(slices.Clone(l.errorKinds), func(k errorKind) bool { return k.target == target })
+	if kind != "" {
+		kinds = append(kinds, errorKind{target: target, kind: kind, level: logLevel})
+	}
+
+	l.errorKinds = kinds
+}
+
+// classifyError returns the kind of the error and its level, zero if none.
+func (l *GormLogger) classifyError(err error) (string, logger.LogLevel) {
+	for _, k := range l.errorKinds {
+		if errors.Is(err, k.target) {
+			return k.kind, k.level
+		}
+	}
+
+	return "", 0
+}
+
+// CancellationLevel sets a log level for traces of statements failed with
+// context.Canceled or context.DeadlineExceeded, e.g. on client disconnects,
+// which is logger.Warn by default. The traces get the cancelled field.
+// logger.Silent disables logging of the failures.
+func (l *GormLogger) CancellationLevel(logLevel logger.LogLevel) {
+	l.cancelLevel = logLevel
+}
+
+// isCancellation reports whether the error is a context cancellation.
+func isCancellation(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// WithErrorLevelMapper returns a copy of the logger choosing the log level of
+// traces of failed statements by their error with the mapper, e.g. to log
+// unique constraint violations as warnings. A zero level returned by the
+// mapper keeps the default level. logger.Silent disables logging of the
+// failure.
+func (l *GormLogger) WithErrorLevelMapper(mapper func(err error) logger.LogLevel) *GormLogger {
+	c := l.clone()
+	c.errorLevelMapper = mapper
+	return c
+}
+
+// WithErrorFilter returns a copy of the logger deciding with the filter by
+// the error and the SQL whether a failed statement is logged as an error.
+// Statements rejected by the filter are ignored like with IgnoreErrors and
+// traced as successful, so their error logs are dropped, or downgraded to the
+// info level if it's enabled. The filter may be called more than once per
+// statement.
+func (l *GormLogger) WithErrorFilter(filter func(err error, sql string) bool) *GormLogger {
+	c := l.clone()
+	c.errorFilter = filter
+	return c
+}
+
+// filterError calls the error filter, logging the error if it panics.
+func (l *GormLogger) filterError(err error, sql string) (logged bool) {
+	defer func() {
+		if recover() != nil {
+			logged = true
+		}
+	}()
+
+	return l.errorFilter(err, sql)
+}
+
+// errorLevel returns the level of the error trace of the record, which error
+// is classified with the kindLevel.
+func (l *GormLogger) errorLevel(rec *TraceRecord, kindLevel logger.LogLevel) logger.LogLevel {
+	if l.errorLevelMapper != nil {
+		if level := l.mapErrorLevel(rec.Err); level != 0 {
+			return level
+		}
+	}
+
+	if kindLevel != 0 {
+		return kindLevel
+	}
+
+	if rec.Cancelled {
+		return l.cancelLevel
+	}
+
+	return logger.Error
+}
+
+// mapErrorLevel calls the error level mapper, recovering from its panics.
+func (l *GormLogger) mapErrorLevel(err error) (level logger.LogLevel) {
+	defer func() {
+		if recover() != nil {
+			level = 0
+		}
+	}()
+
+	return l.errorLevelMapper(err)
+}
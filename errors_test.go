@@ -0,0 +1,160 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLoggerCancellationLevel(t *testing.T) {
+	assert := assert.New(t)
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	nop := func() Event { return &testingEvent{} }
+	l := NewGormLogger().WithInfo(nop).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	trace := func(err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 0 }, err)
+	}
+
+	trace(context.Canceled)
+	trace(fmt.Errorf("query: %w", context.DeadlineExceeded))
+	trace(errors.New("locked"))
+	require.Len(t, warnSink.events, 2)
+	require.Len(t, errorSink.events, 1)
+	for _, e := range warnSink.events {
+		assert.Equal(true, e.fields["cancelled"])
+	}
+	assert.NotContains(errorSink.events[0].fields, "cancelled")
+
+	l.CancellationLevel(logger.Error)
+	trace(context.Canceled)
+	assert.Len(errorSink.events, 2)
+
+	l.CancellationLevel(logger.Silent)
+	trace(context.Canceled)
+	assert.Len(warnSink.events, 2)
+	assert.Len(errorSink.events, 2)
+}
+
+func TestGormLoggerWithErrorLevelMapper(t *testing.T) {
+	assert := assert.New(t)
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	nop := func() Event { return &testingEvent{} }
+	errUnique := errors.New("UNIQUE constraint failed")
+	base := NewGormLogger().WithInfo(nop).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	l := base.WithErrorLevelMapper(func(err error) logger.LogLevel {
+		switch {
+		case errors.Is(err, errUnique):
+			return logger.Warn
+		case errors.Is(err, context.Canceled):
+			return logger.Silent
+		case err.Error() == "panic":
+			panic("test")
+		}
+
+		return 0
+	})
+	trace := func(err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 0 }, err)
+	}
+
+	trace(errUnique)
+	trace(context.Canceled)
+	trace(context.DeadlineExceeded)
+	trace(errors.New("connection refused"))
+	trace(errors.New("panic"))
+	assert.Len(warnSink.events, 2)
+	assert.Len(errorSink.events, 2)
+	assert.Contains(warnSink.events[0].msg, "UNIQUE constraint failed")
+
+	base.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 0 }, errUnique)
+	assert.Len(warnSink.events, 2)
+	assert.Len(errorSink.events, 3)
+}
+
+func TestGormLoggerSetErrorKind(t *testing.T) {
+	assert := assert.New(t)
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	nop := func() Event { return &testingEvent{} }
+	errCheck := errors.New("check constraint violated")
+	l := NewGormLogger().WithInfo(nop).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	l.SetErrorKind(gorm.ErrDuplicatedKey, "duplicated_key", logger.Warn)
+	l.SetErrorKind(errCheck, "check_violated", 0)
+	trace := func(err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "INSERT INTO users (id) VALUES (1)", 0 }, err)
+	}
+
+	trace(fmt.Errorf("create: %w", gorm.ErrDuplicatedKey))
+	trace(gorm.ErrForeignKeyViolated)
+	trace(errCheck)
+	trace(errors.New("locked"))
+	require.Len(t, warnSink.events, 1)
+	require.Len(t, errorSink.events, 3)
+	assert.Equal("duplicated_key", warnSink.events[0].added["error_kind"])
+	assert.Equal("foreign_key_violated", errorSink.events[0].added["error_kind"])
+	assert.Equal("check_violated", errorSink.events[1].added["error_kind"])
+	assert.NotContains(errorSink.events[2].added, "error_kind")
+
+	l.SetErrorKind(gorm.ErrForeignKeyViolated, "", 0)
+	trace(gorm.ErrForeignKeyViolated)
+	assert.NotContains(errorSink.events[3].added, "error_kind")
+	assert.Len(NewGormLogger().errorKinds, 2, "defaults should not be modified")
+}
+
+func TestGormLoggerIgnoreErrors(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	errorSink := &testingSink{}
+	errLockBusy := errors.New("advisory lock busy")
+	errStale := errors.New("stale read")
+	l := NewGormLogger().WithInfo(infoSink.factory).WithError(errorSink.factory)
+	l.IgnoreErrors(errLockBusy)
+	l.IgnoreErrors(errStale)
+	trace := func(err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT pg_try_advisory_lock(1)", 0 }, err)
+	}
+
+	trace(fmt.Errorf("lock: %w", errLockBusy))
+	trace(errStale)
+	trace(gorm.ErrRecordNotFound)
+	assert.Len(infoSink.events, 3)
+	assert.Len(errorSink.events, 1)
+}
+
+func TestGormLoggerWithErrorFilter(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	errorSink := &testingSink{}
+	base := NewGormLogger().WithInfo(infoSink.factory).WithError(errorSink.factory)
+	l := base.WithErrorFilter(func(err error, sql string) bool {
+		if sql == "panic" {
+			panic("test")
+		}
+
+		return !(errors.Is(err, gorm.ErrDuplicatedKey) && strings.HasPrefix(sql, "INSERT INTO sessions"))
+	})
+	trace := func(l *GormLogger, sql string) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 0 }, gorm.ErrDuplicatedKey)
+	}
+
+	trace(l, "INSERT INTO sessions (id) VALUES (1)")
+	assert.Empty(errorSink.events)
+	assert.Len(infoSink.events, 1)
+
+	trace(l, "INSERT INTO users (id) VALUES (1)")
+	trace(l, "panic")
+	assert.Len(errorSink.events, 2)
+
+	trace(base, "INSERT INTO sessions (id) VALUES (1)")
+	assert.Len(errorSink.events, 3)
+}
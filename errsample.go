@@ -0,0 +1,100 @@
+package gormzerolog
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// ErrorSampling is a policy of sampling of repeated errors. Errors are grouped
+// by their type and message and the shape of the failed statement, with
+// literals ignored. The first occurrence of a group is always logged.
+type ErrorSampling struct {
+	// Every logs every Nth occurrence of a group after the first one.
+	Every int
+	// Backoff logs the 2nd, 4th, 8th, ... occurrences of a group if Every is zero.
+	Backoff bool
+	// Size is the number of most recently seen groups remembered. Default is 1024.
+	Size int
+}
+
+type errorGroup struct {
+	key        uint64
+	count      int64
+	suppressed int64
+}
+
+// errorSampler keeps occurrence counts of a bounded number of error groups.
+type errorSampler struct {
+	policy ErrorSampling
+	mu     sync.Mutex
+	order  *list.List
+	groups map[uint64]*list.Element
+}
+
+func newErrorSampler(policy ErrorSampling) *errorSampler {
+	if policy.Size <= 0 {
+		policy.Size = 1024
+	}
+
+	return &errorSampler{
+		policy: policy,
+		order:  list.New(),
+		groups: make(map[uint64]*list.Element, policy.Size),
+	}
+}
+
+// sample counts an occurrence of the group and reports whether it is logged
+// and how many occurrences were suppressed since the last logged one.
+func (s *errorSampler) sample(key uint64) (keep bool, suppressed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.groups[key]
+	if !ok {
+		if s.order.Len() >= s.policy.Size {
+			oldest := s.order.Back()
+			delete(s.groups, oldest.Value.(*errorGroup).key)
+			s.order.Remove(oldest)
+		}
+
+		e = s.order.PushFront(&errorGroup{key: key})
+		s.groups[key] = e
+	} else {
+		s.order.MoveToFront(e)
+	}
+
+	g := e.Value.(*errorGroup)
+	g.count++
+	switch {
+	case g.count == 1:
+		keep = true
+	case s.policy.Every > 0:
+		keep = (g.count-1)%int64(s.policy.Every) == 0
+	case s.policy.Backoff:
+		keep = g.count&(g.count-1) == 0
+	default:
+		keep = true
+	}
+
+	if !keep {
+		g.suppressed++
+		return false, 0
+	}
+
+	suppressed, g.suppressed = g.suppressed, 0
+	return true, suppressed
+}
+
+// SampleErrors enables sampling of repeated errors by the policy, independent
+// of samplers of events. Traces of suppressed errors are not logged at all,
+// the next logged occurrence carries the number of suppressed ones in
+// the suppressed_since_last field. A zero policy logs all errors.
+func (l *GormLogger) SampleErrors(policy ErrorSampling) {
+	l.errorSampler = newErrorSampler(policy)
+}
+
+// errorGroupKey returns a key grouping errors of the same type and message
+// of statements of the same shape.
+func errorGroupKey(err error, sql string) uint64 {
+	return normalizedFingerprint(fmt.Sprintf("%T\x00%s\x00%s", err, normalizeSQL(err.Error()), normalizeSQL(sql)))
+}
@@ -0,0 +1,96 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLoggerSampleErrors(t *testing.T) {
+	newLogger := func(policy ErrorSampling) (*GormLogger, *testingSink) {
+		sink := &testingSink{}
+		l := NewGormLogger().WithError(sink.factory).WithInfo(func() Event { return &testingEvent{} })
+		l.SampleErrors(policy)
+		return l, sink
+	}
+
+	fail := func(l *GormLogger, sql string, err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 0 }, err)
+	}
+
+	suppressed := func(sink *testingSink) []any {
+		var counts []any
+		for _, e := range sink.events {
+			counts = append(counts, e.fields["suppressed_since_last"])
+		}
+
+		return counts
+	}
+
+	t.Run("every", func(t *testing.T) {
+		l, sink := newLogger(ErrorSampling{Every: 3})
+		for i := 0; i < 8; i++ {
+			fail(l, fmt.Sprintf("INSERT INTO users VALUES (%d)", i), fmt.Errorf("duplicate key %d", i))
+		}
+
+		// occurrences 1, 4 and 7 are logged
+		assert.Equal(t, []any{nil, int64(2), int64(2)}, suppressed(sink))
+		fail(l, "INSERT INTO orders VALUES (1)", errors.New("duplicate key 1"))
+		fail(l, "INSERT INTO users VALUES (1)", errors.New("connection refused"))
+		fail(l, "INSERT INTO users VALUES (1)", logger.ErrRecordNotFound)
+		assert.Len(t, sink.events, 6, "new shapes always surface")
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		l, sink := newLogger(ErrorSampling{Backoff: true})
+		for i := 0; i < 20; i++ {
+			fail(l, "SELECT * FROM users WHERE id = 1", errors.New("timeout"))
+		}
+
+		// occurrences 1, 2, 4, 8 and 16 are logged
+		assert.Equal(t, []any{nil, nil, int64(1), int64(3), int64(7)}, suppressed(sink))
+	})
+
+	t.Run("eviction", func(t *testing.T) {
+		l, sink := newLogger(ErrorSampling{Every: 100, Size: 2})
+		for _, table := range []string{"a", "a", "b", "c", "a"} {
+			fail(l, "SELECT * FROM "+table, errors.New("timeout"))
+		}
+
+		assert.Len(t, sink.events, 4, "a is evicted by c and logged again")
+		assert.Len(t, l.errorSampler.groups, 2)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		l, sink := newLogger(ErrorSampling{Every: 10})
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 125; j++ {
+					fail(l, "SELECT 1", errors.New("timeout"))
+				}
+			}()
+		}
+
+		wg.Wait()
+		// occurrences 1, 11, ..., 991 are logged
+		require.Len(t, sink.events, 100)
+		var total int64
+		for _, n := range suppressed(sink) {
+			if n != nil {
+				total += n.(int64)
+			}
+		}
+
+		assert.Equal(t, int64(99*9), total)
+	})
+}
@@ -0,0 +1,86 @@
+package gormzerolog
+
+// FieldNames are names of the main fields of trace events, which can be
+// renamed to match a logging schema, e.g. sql to db.statement. The fields of
+// the number of rows are renamed with RowsFieldNames.
+type FieldNames struct {
+	// SQL is the statement. Default is "sql".
+	SQL string
+	// SQLNormalized is the statement logged by NormalizedSQL.
+	// Default is "sql_normalized".
+	SQLNormalized string
+	// QueryFingerprint is the hash logged by QueryFingerprints.
+	// Default is "query_fingerprint".
+	QueryFingerprint string
+	// Elapsed is the elapsed time written with Event.Dur. Default is "elapsed".
+	Elapsed string
+	// ElapsedMs is the elapsed time in milliseconds logged with the Structured
+	// placement. Default is "elapsed_ms".
+	ElapsedMs string
+	// Caller is the caller logged with CallerField. Default is "caller".
+	Caller string
+	// Operation is the statement verb. Default is "operation".
+	Operation string
+	// Table is the table of the statement. Default is "table".
+	Table string
+	// Model is the model of the statement. Default is "model".
+	Model string
+	// StatementID is the ID of the statement. Default is "stmt_id".
+	StatementID string
+	// Slow flags slow queries. Default is "slow".
+	Slow string
+	// SlowThreshold is the slow threshold in milliseconds logged on slow
+	// queries. Default is "slow_threshold_ms".
+	SlowThreshold string
+	// TraceID is the trace ID logged with WithSpanExtractor.
+	// Default is "trace_id".
+	TraceID string
+	// SpanID is the span ID logged with WithSpanExtractor.
+	// Default is "span_id".
+	SpanID string
+}
+
+var defaultFieldNames = FieldNames{
+	SQL:              "sql",
+	SQLNormalized:    "sql_normalized",
+	QueryFingerprint: "query_fingerprint",
+	Elapsed:          "elapsed",
+	ElapsedMs:        "elapsed_ms",
+	Caller:           "caller",
+	Operation:        "operation",
+	Table:            "table",
+	Model:            "model",
+	StatementID:      "stmt_id",
+	Slow:             "slow",
+	SlowThreshold:    "slow_threshold_ms",
+	TraceID:          "trace_id",
+	SpanID:           "span_id",
+}
+
+// SetFieldNames sets names of the main fields of trace events.
+// Empty names keep their current values.
+func (l *GormLogger) SetFieldNames(names FieldNames) {
+	for _, p := range []struct {
+		dst *string
+		src string
+	}{
+		{&l.fieldNames.SQL, names.SQL},
+		{&l.fieldNames.SQLNormalized, names.SQLNormalized},
+		{&l.fieldNames.QueryFingerprint, names.QueryFingerprint},
+		{&l.fieldNames.Elapsed, names.Elapsed},
+		{&l.fieldNames.ElapsedMs, names.ElapsedMs},
+		{&l.fieldNames.Caller, names.Caller},
+		{&l.fieldNames.Operation, names.Operation},
+		{&l.fieldNames.Table, names.Table},
+		{&l.fieldNames.Model, names.Model},
+		{&l.fieldNames.StatementID, names.StatementID},
+		{&l.fieldNames.Slow, names.Slow},
+		{&l.fieldNames.SlowThreshold, names.SlowThreshold},
+		{&l.fieldNames.TraceID, names.TraceID},
+		{&l.fieldNames.SpanID, names.SpanID},
+	} {
+		if p.src != "" {
+			*p.dst = p.src
+		}
+	}
+}
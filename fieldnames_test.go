@@ -0,0 +1,37 @@
+package gormzerolog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormLoggerSetFieldNames(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithClock(clock)
+	l.SlowThreshold(100 * time.Millisecond)
+	l.SQLPlacement(Structured)
+	l.SetFieldNames(FieldNames{SQL: "db.statement", ElapsedMs: "duration"})
+	l.SetFieldNames(FieldNames{Operation: "db.operation", Slow: "is_slow"})
+	assert.Equal(defaultFieldNames.Table, l.fieldNames.Table)
+
+	l.Trace(context.Background(), clock.Now().Add(-time.Second), func() (string, int64) { return "SELECT * FROM users", 1 }, nil)
+	require.Len(t, warnSink.events, 1)
+	e := warnSink.events[0]
+	assert.Equal("SELECT * FROM users", e.added["db.statement"])
+	assert.Equal("select", e.added["db.operation"])
+	assert.Equal("users", e.added["table"])
+	assert.Equal(1000.0, e.fields["duration"])
+	assert.Equal(time.Second, e.fields["elapsed"])
+	assert.Equal(true, e.fields["is_slow"])
+	for _, name := range []string{"sql", "operation", "elapsed_ms", "slow"} {
+		assert.NotContains(e.added, name)
+		assert.NotContains(e.fields, name)
+	}
+}
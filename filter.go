@@ -0,0 +1,63 @@
+package gormzerolog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TraceMatcher reports whether the trace record matches.
+type TraceMatcher func(rec *TraceRecord) bool
+
+// MatchTables returns a matcher of statements on any of the tables. Table
+// names are matched case-insensitively, with or without a schema.
+func MatchTables(tables ...string) TraceMatcher {
+	set := make(map[string]struct{}, len(tables))
+	for _, table := range tables {
+		set[strings.ToLower(table)] = struct{}{}
+	}
+
+	return func(rec *TraceRecord) bool {
+		return containsTable(set, rec.Table)
+	}
+}
+
+// MatchSQL returns a matcher of statements matching the regular expression.
+func MatchSQL(re *regexp.Regexp) TraceMatcher {
+	return func(rec *TraceRecord) bool {
+		return re.MatchString(rec.SQL)
+	}
+}
+
+// OnlyLogMatching limits logging of traces to statements matched by any of
+// the matchers, e.g. for an investigation of a few tables. Other statements
+// are still logged if they fail, are slow or are escalated by checks, unless
+// OnlyLogMatchingErrors is set. Matchers are executed after interceptors,
+// so a record dropped by an interceptor is never logged, even if it matches.
+// Stats, metrics and collectors still see all statements.
+// No matchers disable the filter.
+func (l *GormLogger) OnlyLogMatching(matchers ...TraceMatcher) {
+	l.matchers = matchers
+}
+
+// OnlyLogMatchingErrors sets a flag for applying OnlyLogMatching to failed,
+// slow and escalated statements too. Statements escalated by
+// EscalateStatements are logged regardless.
+func (l *GormLogger) OnlyLogMatchingErrors(b bool) {
+	l.matchErrors = b
+}
+
+// matches reports whether the record is matched by the OnlyLogMatching
+// matchers, true if there are none.
+func (l *GormLogger) matches(rec *TraceRecord) bool {
+	if len(l.matchers) == 0 {
+		return true
+	}
+
+	for _, m := range l.matchers {
+		if m(rec) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLoggerOnlyLogMatching(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	l.OnlyLogMatching(
+		MatchTables("Users"),
+		MatchSQL(regexp.MustCompile(`^UPDATE accounts\b`)),
+		func(rec *TraceRecord) bool { return rec.Rows > 100 },
+	)
+	trace := func(sql string, elapsed time.Duration, rows int64, err error) {
+		l.Trace(context.Background(), time.Now().Add(-elapsed), func() (string, int64) { return sql, rows }, err)
+	}
+
+	trace("SELECT * FROM users", 0, 1, nil)
+	trace(`SELECT * FROM "public"."users"`, 0, 1, nil)
+	trace("UPDATE accounts SET active = false", 0, 1, nil)
+	trace("SELECT * FROM orders", 0, 500, nil)
+	require.Len(t, infoSink.events, 4)
+
+	trace("SELECT * FROM orders", 0, 1, nil)
+	trace("UPDATE accountsx SET active = false", 0, 1, nil)
+	assert.Len(infoSink.events, 4, "non-matching statements should be skipped")
+
+	t.Run("errors and slow queries", func(t *testing.T) {
+		trace("SELECT * FROM orders", 0, 0, errors.New("test"))
+		trace("SELECT * FROM orders", time.Second, 1, nil)
+		assert.Len(errorSink.events, 1)
+		assert.Len(warnSink.events, 1)
+		assert.Len(infoSink.events, 4)
+
+		l.OnlyLogMatchingErrors(true)
+		defer l.OnlyLogMatchingErrors(false)
+		trace("SELECT * FROM orders", 0, 0, errors.New("test"))
+		trace("SELECT * FROM orders", time.Second, 1, nil)
+		trace("SELECT * FROM users", 0, 0, errors.New("test"))
+		assert.Len(errorSink.events, 2)
+		assert.Len(warnSink.events, 1)
+
+		require.NoError(t, l.EscalateStatements("DROP TABLE"))
+		trace("DROP TABLE orders", 0, 0, nil)
+		assert.Len(errorSink.events, 3, "dangerous statements should be logged")
+		require.NoError(t, l.EscalateStatements())
+	})
+
+	t.Run("checks", func(t *testing.T) {
+		infos, warns := len(infoSink.events), len(warnSink.events)
+		l.FlagUnboundedSelects(logger.Info, "orders")
+		defer l.FlagUnboundedSelects(logger.Silent)
+		trace("SELECT * FROM orders", 0, 1, nil)
+		assert.Len(infoSink.events, infos, "checks at the info level should not escalate")
+
+		l.FlagUnboundedSelects(logger.Warn, "orders")
+		trace("SELECT * FROM orders", 0, 1, nil)
+		assert.Len(warnSink.events, warns+1)
+	})
+
+	t.Run("interceptors take precedence", func(t *testing.T) {
+		infoSink.events = nil
+		l.Use(func(_ context.Context, rec *TraceRecord) bool { return rec.Operation != "delete" })
+		trace("DELETE FROM users", 0, 1, nil)
+		trace("SELECT * FROM users", 0, 1, nil)
+		require.Len(t, infoSink.events, 1)
+		assert.Contains(infoSink.events[0].msg, "SELECT")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		infoSink.events = nil
+		l.OnlyLogMatching()
+		trace("SELECT * FROM orders", 0, 1, nil)
+		assert.Len(infoSink.events, 1)
+	})
+}
@@ -0,0 +1,70 @@
+package gormzerolog
+
+import (
+	"container/list"
+	"sync"
+)
+
+// seenSet is a set of statement fingerprints bounded by evicting
+// the least recently seen ones.
+type seenSet struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[uint64]*list.Element
+}
+
+func newSeenSet(size int) *seenSet {
+	return &seenSet{
+		size:  size,
+		order: list.New(),
+		items: make(map[uint64]*list.Element, size),
+	}
+}
+
+// add marks the fingerprint as seen and reports whether it is new.
+func (s *seenSet) add(fp uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.items[fp]; ok {
+		s.order.MoveToFront(e)
+		return false
+	}
+
+	if s.order.Len() >= s.size {
+		oldest := s.order.Back()
+		delete(s.items, oldest.Value.(uint64))
+		s.order.Remove(oldest)
+	}
+
+	s.items[fp] = s.order.PushFront(fp)
+	return true
+}
+
+func (s *seenSet) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.order.Init()
+	clear(s.items)
+}
+
+// LogFirstSeen makes the first occurrence of every statement shape logged
+// with the first_seen field, bypassing samplers and the overload protection.
+// Statements of the same shape differ only in literal values. Up to size
+// most recently seen shapes are remembered, so an evicted shape is first seen
+// again. Zero size disables the mode.
+func (l *GormLogger) LogFirstSeen(size int) {
+	if size <= 0 {
+		l.firstSeen = nil
+		return
+	}
+
+	l.firstSeen = newSeenSet(size)
+}
+
+// ResetFirstSeen forgets the seen statement shapes.
+func (l *GormLogger) ResetFirstSeen() {
+	if l.firstSeen != nil {
+		l.firstSeen.reset()
+	}
+}
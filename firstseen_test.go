@@ -0,0 +1,101 @@
+package gormzerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLoggerLogFirstSeen(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	globalLogger := log.Logger
+	log.Logger = zerolog.New(buf)
+	defer func() { log.Logger = globalLogger }()
+
+	l := NewGormLogger().WithLevelSampler(logger.Info, &zerolog.BasicSampler{N: 10})
+	l.LogFirstSeen(2)
+	trace := func(sql string) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	// entries returns the SQL of logged events marked with * if first seen.
+	entries := func() []string {
+		defer buf.Reset()
+		var logged []string
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+
+			entry := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			sql := entry["message"].(string)
+			sql = sql[strings.LastIndex(sql, "] ")+2:]
+			if entry["first_seen"] == true {
+				sql = "*" + sql
+			}
+
+			logged = append(logged, sql)
+		}
+
+		return logged
+	}
+
+	for i := 0; i < 12; i++ {
+		trace("SELECT * FROM users WHERE id = " + strings.Repeat("1", i+1))
+	}
+
+	// the first repeat and the eleventh one pass the 1-in-10 sampler
+	assert.Equal([]string{
+		"*SELECT * FROM users WHERE id = 1",
+		"SELECT * FROM users WHERE id = 11",
+		"SELECT * FROM users WHERE id = 111111111111",
+	}, entries())
+
+	trace("SELECT * FROM orders")
+	trace("SELECT * FROM users WHERE id = 2")
+	trace("SELECT * FROM items")
+	trace("SELECT * FROM users WHERE id = 3")
+	trace("SELECT * FROM orders")
+	assert.Equal([]string{
+		"*SELECT * FROM orders",
+		"*SELECT * FROM items",
+		"*SELECT * FROM orders",
+	}, entries(), "least recently seen orders are evicted by items")
+
+	l.ResetFirstSeen()
+	trace("SELECT * FROM items")
+	assert.Equal([]string{"*SELECT * FROM items"}, entries())
+
+	l.LogFirstSeen(0)
+	trace("SELECT * FROM accounts")
+	assert.Empty(entries())
+}
+
+func TestGormLoggerLogFirstSeenOverload(t *testing.T) {
+	clock := &testingClock{now: time.Now()}
+	sink := &testingSink{}
+	l := NewGormLogger().WithClock(clock).WithInfo(sink.factory).WithWarn(sink.factory)
+	l.OverloadProtection(OverloadConfig{MaxRate: 1, Window: time.Second})
+	l.LogFirstSeen(10)
+	for i := 0; i < 10; i++ {
+		l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		clock.Advance(100 * time.Millisecond)
+	}
+
+	l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "SELECT * FROM users", 1 }, nil)
+	l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "SELECT * FROM users", 1 }, nil)
+	last := sink.events[len(sink.events)-1]
+	assert.Equal(t, true, last.fields["first_seen"])
+	assert.Contains(t, last.msg, "SELECT * FROM users")
+}
@@ -0,0 +1,69 @@
+package gormzerolog_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormzerolog "github.com/vitaliy-art/gorm-zerolog"
+	"github.com/vitaliy-art/gorm-zerolog/gormzerologtest"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGolden(t *testing.T) {
+	gormzerologtest.AssertGoldenScript(t, "testdata/default.golden.ndjson", nil, *update)
+	gormzerologtest.AssertGoldenScript(t, "testdata/debug.golden.ndjson", func(l *gormzerolog.GormLogger) {
+		l.SetLogLevel(gormzerolog.Debug)
+		l.CallerFunc(true)
+		l.AdditionalFields = map[string]any{"service": "users", "shard": 7}
+	}, *update)
+	gormzerologtest.AssertGoldenScript(t, "testdata/inline.golden.ndjson", func(l *gormzerolog.GormLogger) {
+		l.SQLPlacement(gormzerolog.Inline)
+	}, *update)
+	gormzerologtest.AssertGoldenScript(t, "testdata/structured.golden.ndjson", func(l *gormzerolog.GormLogger) {
+		l.SQLPlacement(gormzerolog.Structured)
+	}, *update)
+}
+
+// goldenPreset pins the caller and the time of the preset loggers, which log
+// timestamps.
+func goldenPreset(l *gormzerolog.GormLogger) *gormzerolog.GormLogger {
+	return l.WithCallerResolver(func(context.Context) string { return gormzerologtest.GoldenCaller }).
+		WithClock(gormzerologtest.NewClock(gormzerologtest.GoldenTime))
+}
+
+func setGoldenTimestamps(t *testing.T) {
+	timestampFunc := zerolog.TimestampFunc
+	zerolog.TimestampFunc = func() time.Time { return gormzerologtest.GoldenTime }
+	t.Cleanup(func() { zerolog.TimestampFunc = timestampFunc })
+}
+
+func TestGoldenDevelopmentPreset(t *testing.T) {
+	setGoldenTimestamps(t)
+	buf := &bytes.Buffer{}
+	gormzerologtest.RunScript(goldenPreset(gormzerolog.NewDevelopmentGormLoggerTo(buf)))
+
+	path := "testdata/development.golden.txt"
+	if *update {
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestGoldenProductionPreset(t *testing.T) {
+	setGoldenTimestamps(t)
+	buf := &bytes.Buffer{}
+	gormzerologtest.RunScript(goldenPreset(gormzerolog.NewProductionGormLogger(buf)))
+	gormzerologtest.AssertGolden(t, "testdata/production.golden.ndjson", buf.Bytes(), *update)
+}
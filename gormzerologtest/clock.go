@@ -0,0 +1,94 @@
+package gormzerologtest
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	gormzerolog "github.com/vitaliy-art/gorm-zerolog"
+)
+
+// Clock is a manually driven gormzerolog.TimerClock. Its timers fire in the
+// goroutine advancing the clock. It is safe for concurrent use.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*clockTimer
+}
+
+type clockTimer struct {
+	c  *Clock
+	at time.Time
+	f  func()
+}
+
+// NewClock creates a clock stopped at the given time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d, firing the timers which are due.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// Set moves the clock to the given time, firing the timers which are due.
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.fire()
+}
+
+// AfterFunc starts a timer calling f once the clock is advanced by d.
+func (c *Clock) AfterFunc(d time.Duration, f func()) gormzerolog.Timer {
+	t := &clockTimer{c: c, f: f}
+	c.mu.Lock()
+	t.at = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.fire()
+	return t
+}
+
+// Timers returns the number of timers which are neither fired nor stopped.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// fire calls the due timers in order of their deadlines after unlocking
+// the clock, which must be locked.
+func (c *Clock) fire() {
+	var due []*clockTimer
+	c.timers = slices.DeleteFunc(c.timers, func(t *clockTimer) bool {
+		if t.at.After(c.now) {
+			return false
+		}
+
+		due = append(due, t)
+		return true
+	})
+
+	c.mu.Unlock()
+	slices.SortStableFunc(due, func(a, b *clockTimer) int { return a.at.Compare(b.at) })
+	for _, t := range due {
+		t.f()
+	}
+}
+
+func (t *clockTimer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	n := len(t.c.timers)
+	t.c.timers = slices.DeleteFunc(t.c.timers, func(other *clockTimer) bool { return other == t })
+	return len(t.c.timers) < n
+}
@@ -0,0 +1,64 @@
+package gormzerologtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	gormzerolog "github.com/vitaliy-art/gorm-zerolog"
+	"gorm.io/gorm/logger"
+)
+
+func TestClock(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+	assert.Equal(start, clock.Now())
+	clock.Advance(time.Second)
+	assert.Equal(start.Add(time.Second), clock.Now())
+	clock.Set(start)
+	assert.Equal(start, clock.Now())
+}
+
+func TestClockTimers(t *testing.T) {
+	assert := assert.New(t)
+	clock := NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var fired []int
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+	clock.AfterFunc(time.Second, func() { fired = append(fired, 1) })
+	stopped := clock.AfterFunc(time.Second, func() { fired = append(fired, 0) })
+	assert.Equal(3, clock.Timers())
+	assert.True(stopped.Stop())
+	assert.False(stopped.Stop())
+
+	clock.Advance(time.Second - 1)
+	assert.Empty(fired)
+	clock.Advance(time.Hour)
+	assert.Equal([]int{1, 2}, fired)
+	assert.Zero(clock.Timers())
+
+	clock.AfterFunc(0, func() { fired = append(fired, 3) })
+	assert.Equal([]int{1, 2, 3}, fired)
+}
+
+func TestClockSlowThreshold(t *testing.T) {
+	rec := NewRecorder(t)
+	clock := NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	l := gormzerolog.NewGormLogger().
+		WithInfo(rec.Factory(logger.Info)).
+		WithWarn(rec.Factory(logger.Warn)).
+		WithClock(clock)
+	l.SlowThreshold(time.Millisecond * 100)
+
+	for _, elapsed := range []time.Duration{time.Millisecond * 100, time.Millisecond * 101} {
+		begin := clock.Now()
+		clock.Advance(elapsed)
+		l.Trace(context.Background(), begin, func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	rec.AssertCount(logger.Warn, 1)
+	rec.AssertMessageContains(logger.Warn, "[101.000ms]")
+	rec.AssertCount(logger.Info, 2)
+	rec.AssertMessageContains(logger.Info, "[100.000ms]")
+}
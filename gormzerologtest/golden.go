@@ -0,0 +1,139 @@
+package gormzerologtest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	gormzerolog "github.com/vitaliy-art/gorm-zerolog"
+)
+
+// GoldenCaller is the caller reported by loggers created with NewJSONLogger.
+const GoldenCaller = "app/users.go:42"
+
+// GoldenTime is the time of the clock used by loggers created with NewJSONLogger.
+var GoldenTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// NewJSONLogger creates a logger writing JSON events without timestamps into
+// the buffer, with a fixed caller and clock, so its output is deterministic.
+func NewJSONLogger(buf *bytes.Buffer) *gormzerolog.GormLogger {
+	zl := zerolog.New(buf)
+	l := gormzerolog.NewGormLogger().
+		WithDebug(func() gormzerolog.Event { return &gormzerolog.GormLoggerEvent{Event: zl.Debug()} }).
+		WithInfo(func() gormzerolog.Event { return &gormzerolog.GormLoggerEvent{Event: zl.Info()} }).
+		WithWarn(func() gormzerolog.Event { return &gormzerolog.GormLoggerEvent{Event: zl.Warn()} }).
+		WithError(func() gormzerolog.Event { return &gormzerolog.GormLoggerEvent{Event: zl.Error()} }).
+		WithCallerResolver(func(context.Context) string { return GoldenCaller }).
+		WithClock(NewClock(GoldenTime))
+	l.DisableBanner(true)
+	return l
+}
+
+// RunScript traces a fixed set of statements with the logger: a successful
+// query, a query with unknown rows, a slow query, a failed query and a query
+// logged with additional data. Elapsed times are relative to GoldenTime.
+func RunScript(l *gormzerolog.GormLogger) {
+	ctx := context.Background()
+	now := GoldenTime
+	l.Trace(ctx, now.Add(-time.Millisecond*3), func() (string, int64) {
+		return "SELECT * FROM `users` WHERE `users`.`id` = 1", 1
+	}, nil)
+	l.Trace(ctx, now.Add(-time.Microsecond*1500), func() (string, int64) {
+		return "UPDATE `users` SET `active` = false", -1
+	}, nil)
+	l.Trace(ctx, now.Add(-time.Second), func() (string, int64) {
+		return "SELECT * FROM `orders` WHERE `total` > 100", 42
+	}, nil)
+	l.Trace(ctx, now.Add(-time.Millisecond), func() (string, int64) {
+		return "INSERT INTO `users` (`email`) VALUES (\"a@example.com\")", 0
+	}, errors.New("UNIQUE constraint failed: users.email"))
+	l.WithFields(map[string]string{"request_id": "req-1"}).Trace(ctx, now, func() (string, int64) {
+		return "DELETE FROM `sessions` WHERE `user_id` = 1", 3
+	}, nil)
+	l.Info(ctx, "migrated %d tables", 2)
+	l.Warn(ctx, "deprecated option %s", "foo")
+	l.Error(ctx, "connection lost")
+}
+
+// NormalizeJSON rewrites NDJSON with sorted keys, so it can be compared with golden files.
+func NormalizeJSON(ndjson []byte) ([]byte, error) {
+	out := &bytes.Buffer{}
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	scanner := bufio.NewScanner(bytes.NewReader(ndjson))
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		entry := map[string]any{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+
+		if err := enc.Encode(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	return out.Bytes(), scanner.Err()
+}
+
+// AssertGolden compares normalized NDJSON with the golden file.
+// If update is true, the golden file is overwritten instead.
+func AssertGolden(tb testing.TB, path string, ndjson []byte, update bool) bool {
+	tb.Helper()
+	got, err := NormalizeJSON(ndjson)
+	if err != nil {
+		tb.Errorf("normalize output: %v", err)
+		return false
+	}
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Errorf("create golden file directory: %v", err)
+			return false
+		}
+
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			tb.Errorf("update golden file: %v", err)
+			return false
+		}
+
+		return true
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		tb.Errorf("read golden file: %v", err)
+		return false
+	}
+
+	if !bytes.Equal(expected, got) {
+		tb.Errorf("output differs from golden file %s\nexpected:\n%s\ngot:\n%s", path, expected, got)
+		return false
+	}
+
+	return true
+}
+
+// AssertGoldenScript runs the script against a JSON logger configured with
+// configure and compares the output with the golden file.
+func AssertGoldenScript(tb testing.TB, path string, configure func(*gormzerolog.GormLogger), update bool) bool {
+	tb.Helper()
+	buf := &bytes.Buffer{}
+	l := NewJSONLogger(buf)
+	if configure != nil {
+		configure(l)
+	}
+
+	RunScript(l)
+	return AssertGolden(tb, path, buf.Bytes(), update)
+}
@@ -0,0 +1,166 @@
+// Package gormzerologtest provides utilities for testing code logging with gormzerolog.
+package gormzerologtest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gormzerolog "github.com/vitaliy-art/gorm-zerolog"
+	"gorm.io/gorm/logger"
+)
+
+// Entry is a captured log event.
+type Entry struct {
+	Level   logger.LogLevel
+	Fields  map[string]any
+	Message string
+}
+
+func (e Entry) String() string {
+	return fmt.Sprintf("[%s] %q %v", gormzerolog.LevelName(e.Level), e.Message, e.Fields)
+}
+
+// Recorder captures events logged by a GormLogger. It is safe for concurrent use.
+type Recorder struct {
+	tb      testing.TB
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder creates a new recorder reporting assertion failures to tb.
+func NewRecorder(tb testing.TB) *Recorder {
+	return &Recorder{tb: tb}
+}
+
+// Capture creates a logger in debug mode with all event builders writing to
+// a new recorder. The recorder is reset when the test finishes.
+func Capture(tb testing.TB) (*gormzerolog.GormLogger, *Recorder) {
+	r := NewRecorder(tb)
+	tb.Cleanup(r.Reset)
+	l := gormzerolog.NewGormLogger().
+		WithDebug(r.Factory(gormzerolog.Debug)).
+		WithInfo(r.Factory(logger.Info)).
+		WithWarn(r.Factory(logger.Warn)).
+		WithError(r.Factory(logger.Error))
+	l.SetLogLevel(gormzerolog.Debug)
+	return l, r
+}
+
+// Factory returns an event builder recording events of the given level.
+func (r *Recorder) Factory(level logger.LogLevel) func() gormzerolog.Event {
+	return func() gormzerolog.Event {
+		return &event{recorder: r, entry: Entry{Level: level, Fields: map[string]any{}}}
+	}
+}
+
+// Entries returns a copy of the captured events.
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := make([]Entry, len(r.entries))
+	copy(entries, r.entries)
+	return entries
+}
+
+// Level returns the captured events of the level.
+func (r *Recorder) Level(level logger.LogLevel) []Entry {
+	var entries []Entry
+	for _, e := range r.Entries() {
+		if e.Level == level {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// Reset removes all captured events.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// AssertCount checks that exactly n events of the level were captured.
+func (r *Recorder) AssertCount(level logger.LogLevel, n int) bool {
+	r.tb.Helper()
+	if got := len(r.Level(level)); got != n {
+		return r.fail("expected %d %s events, got %d", n, gormzerolog.LevelName(level), got)
+	}
+
+	return true
+}
+
+// AssertFieldEquals checks that an event of the level has the field with the value.
+func (r *Recorder) AssertFieldEquals(level logger.LogLevel, key string, value any) bool {
+	r.tb.Helper()
+	for _, e := range r.Level(level) {
+		if v, ok := e.Fields[key]; ok && reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+
+	return r.fail("expected a %s event with %s=%v", gormzerolog.LevelName(level), key, value)
+}
+
+// AssertMessageContains checks that a message of an event of the level contains substr.
+func (r *Recorder) AssertMessageContains(level logger.LogLevel, substr string) bool {
+	r.tb.Helper()
+	for _, e := range r.Level(level) {
+		if strings.Contains(e.Message, substr) {
+			return true
+		}
+	}
+
+	return r.fail("expected a %s event with message containing %q", gormzerolog.LevelName(level), substr)
+}
+
+func (r *Recorder) fail(format string, args ...any) bool {
+	r.tb.Helper()
+	b := &strings.Builder{}
+	fmt.Fprintf(b, format, args...)
+	entries := r.Entries()
+	fmt.Fprintf(b, "\ncaptured %d events:", len(entries))
+	for _, e := range entries {
+		b.WriteString("\n\t")
+		b.WriteString(e.String())
+	}
+
+	r.tb.Error(b.String())
+	return false
+}
+
+func (r *Recorder) add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+}
+
+type event struct {
+	recorder *Recorder
+	entry    Entry
+}
+
+func (e *event) Str(key, value string) gormzerolog.Event {
+	e.entry.Fields[key] = value
+	return e
+}
+
+func (e *event) Any(key string, value any) gormzerolog.Event {
+	e.entry.Fields[key] = value
+	return e
+}
+
+func (e *event) Dur(key string, d time.Duration) gormzerolog.Event {
+	e.entry.Fields[key] = d
+	return e
+}
+
+func (e *event) Msgf(format string, v ...any) {
+	e.entry.Message = fmt.Sprintf(format, v...)
+	e.recorder.add(e.entry)
+}
@@ -0,0 +1,72 @@
+package gormzerologtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	gormzerolog "github.com/vitaliy-art/gorm-zerolog"
+	"gorm.io/gorm/logger"
+)
+
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Error(args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprint(args...))
+}
+
+func TestRecorder(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			l, r := Capture(t)
+			l.SlowThreshold(time.Millisecond)
+			l.AdditionalFields = map[string]any{"subtest": name}
+			l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT * FROM orders", 1 }, nil)
+			l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("failed"))
+
+			r.AssertCount(logger.Warn, 1)
+			r.AssertCount(logger.Error, 1)
+			r.AssertCount(logger.Info, 2)
+			r.AssertMessageContains(logger.Warn, "orders")
+			r.AssertMessageContains(logger.Error, "failed")
+			r.AssertFieldEquals(logger.Info, "subtest", name)
+
+			r.Reset()
+			r.AssertCount(logger.Info, 0)
+			assert.Empty(t, r.Entries())
+		})
+	}
+}
+
+func TestRecorderFailures(t *testing.T) {
+	assert := assert.New(t)
+	tb := &fakeTB{}
+	r := NewRecorder(tb)
+	l := gormzerolog.NewGormLogger().WithInfo(r.Factory(logger.Info))
+	l.AdditionalData = map[string]string{"table": "orders"}
+	l.Info(context.Background(), "hello %s", "world")
+
+	assert.True(r.AssertCount(logger.Info, 1))
+	assert.True(r.AssertFieldEquals(logger.Info, "table", "orders"))
+	assert.True(r.AssertMessageContains(logger.Info, "world"))
+	assert.Empty(tb.errors)
+
+	assert.False(r.AssertCount(logger.Warn, 1))
+	assert.False(r.AssertFieldEquals(logger.Info, "table", "users"))
+	assert.False(r.AssertMessageContains(logger.Info, "orders"))
+	if assert.Len(tb.errors, 3) {
+		assert.Equal("expected 1 warn events, got 0\ncaptured 1 events:\n\t[info] \"hello world\" map[table:orders]", tb.errors[0])
+		assert.Contains(tb.errors[1], "expected a info event with table=users\ncaptured 1 events:")
+		assert.Contains(tb.errors[2], `expected a info event with message containing "orders"`)
+	}
+}
@@ -0,0 +1,109 @@
+package gormzerologtest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	gormzerolog "github.com/vitaliy-art/gorm-zerolog"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// TraceRecorder is a gorm logger storing every traced statement, so tests of
+// data access code can assert on executed SQL. Messages logged with Info,
+// Warn and Error are discarded. It is safe for concurrent use.
+type TraceRecorder struct {
+	mu      sync.Mutex
+	records []gormzerolog.TraceRecord
+}
+
+// NewTraceRecorder creates a new trace recorder.
+func NewTraceRecorder() *TraceRecorder {
+	return &TraceRecorder{}
+}
+
+// LogMode returns the recorder itself, statements are recorded at any level.
+func (r *TraceRecorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+func (r *TraceRecorder) Info(context.Context, string, ...any) {}
+
+func (r *TraceRecorder) Warn(context.Context, string, ...any) {}
+
+func (r *TraceRecorder) Error(context.Context, string, ...any) {}
+
+// Trace records the statement.
+func (r *TraceRecorder) Trace(_ context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	rec := gormzerolog.TraceRecord{
+		Begin:   begin,
+		Elapsed: elapsed,
+		SQL:     sql,
+		Rows:    rows,
+		Err:     err,
+		Caller:  utils.FileWithLineNum(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, rec)
+}
+
+// Records returns a copy of the recorded statements in the order they were traced.
+func (r *TraceRecorder) Records() []gormzerolog.TraceRecord {
+	return r.filter(func(gormzerolog.TraceRecord) bool { return true })
+}
+
+// Statements returns the SQL of the recorded statements.
+func (r *TraceRecorder) Statements() []string {
+	records := r.Records()
+	statements := make([]string, 0, len(records))
+	for _, rec := range records {
+		statements = append(statements, rec.SQL)
+	}
+
+	return statements
+}
+
+// SQLContaining returns the recorded statements with SQL containing substr.
+func (r *TraceRecorder) SQLContaining(substr string) []gormzerolog.TraceRecord {
+	return r.filter(func(rec gormzerolog.TraceRecord) bool {
+		return strings.Contains(rec.SQL, substr)
+	})
+}
+
+// Errors returns the errors of the failed statements.
+func (r *TraceRecorder) Errors() []error {
+	var errs []error
+	for _, rec := range r.Records() {
+		if rec.Err != nil {
+			errs = append(errs, rec.Err)
+		}
+	}
+
+	return errs
+}
+
+// Reset removes the recorded statements.
+func (r *TraceRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
+
+func (r *TraceRecorder) filter(keep func(gormzerolog.TraceRecord) bool) []gormzerolog.TraceRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var records []gormzerolog.TraceRecord
+	for _, rec := range r.records {
+		if keep(rec) {
+			records = append(records, rec)
+		}
+	}
+
+	return records
+}
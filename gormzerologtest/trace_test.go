@@ -0,0 +1,54 @@
+package gormzerologtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestTraceRecorder(t *testing.T) {
+	assert := assert.New(t)
+	r := NewTraceRecorder()
+	var l logger.Interface = r
+	l = l.LogMode(logger.Silent)
+	ctx := context.Background()
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT * FROM users", 2 }, nil)
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "INSERT INTO users", 0 }, errors.New("test"))
+	l.Info(ctx, "ignored")
+
+	assert.Equal([]string{"SELECT * FROM users", "INSERT INTO users"}, r.Statements())
+	require.Len(t, r.SQLContaining("SELECT"), 1)
+	assert.Equal(int64(2), r.SQLContaining("SELECT")[0].Rows)
+	assert.Contains(r.SQLContaining("SELECT")[0].Caller, "trace_test.go:")
+	assert.Equal([]error{errors.New("test")}, r.Errors())
+
+	r.Reset()
+	assert.Empty(r.Records())
+	assert.Empty(r.Errors())
+}
+
+func TestTraceRecorderConcurrent(t *testing.T) {
+	r := NewTraceRecorder()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				r.Trace(context.Background(), time.Now(), func() (string, int64) { return fmt.Sprintf("SELECT %d", i), 1 }, nil)
+				r.Statements()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	assert.Len(t, r.Records(), 100)
+	assert.Len(t, r.SQLContaining("SELECT 3"), 10)
+}
@@ -0,0 +1,24 @@
+package gormzerolog
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/hlog"
+)
+
+// WithRequestID returns a copy of the logger adding the req_id field to
+// every event of a context carrying a request ID set by the
+// hlog.RequestIDHandler middleware, so queries can be joined with the
+// request logs.
+func (l *GormLogger) WithRequestID() *GormLogger {
+	return l.WithContextExtractor(requestIDFields)
+}
+
+func requestIDFields(ctx context.Context) map[string]any {
+	id, ok := hlog.IDFromCtx(ctx)
+	if !ok {
+		return nil
+	}
+
+	return map[string]any{"req_id": id.String()}
+}
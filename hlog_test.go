@@ -0,0 +1,33 @@
+package gormzerolog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/hlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormLoggerWithRequestID(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithRequestID()
+	var id string
+	handler := hlog.RequestIDHandler("req_id", "")(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		reqID, ok := hlog.IDFromRequest(r)
+		require.True(t, ok)
+		id = reqID.String()
+		l.Trace(r.Context(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+	require.Len(t, sink.events, 2)
+	assert.NotEmpty(id)
+	assert.Equal(id, sink.events[0].fields["req_id"])
+	assert.NotContains(sink.events[1].fields, "req_id")
+}
@@ -0,0 +1,694 @@
+package gormzerolog_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormzerolog "github.com/vitaliy-art/gorm-zerolog"
+	"github.com/vitaliy-art/gorm-zerolog/gormzerologtest"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type Order struct {
+	ID    uint
+	Total int
+}
+
+func (o *Order) AfterFind(*gorm.DB) error {
+	time.Sleep(time.Millisecond * 5)
+	return nil
+}
+
+func TestIntegration(t *testing.T) {
+	l, r := gormzerologtest.Capture(t)
+	l.SetLogLevel(logger.Info)
+	l.SlowThreshold(time.Millisecond)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+	r.AssertMessageContains(logger.Info, "gorm zerolog logger attached")
+	require.NoError(t, db.AutoMigrate(&Order{}))
+	require.NoError(t, db.Create(&Order{Total: 10}).Error)
+
+	t.Run("slow query", func(t *testing.T) {
+		r.Reset()
+		require.NoError(t, db.Find(&[]Order{}).Error)
+		r.AssertCount(logger.Warn, 1)
+		r.AssertMessageContains(logger.Warn, "SLOW SQL")
+		r.AssertMessageContains(logger.Warn, "orders")
+	})
+
+	t.Run("record not found", func(t *testing.T) {
+		r.Reset()
+		err := db.Session(&gorm.Session{}).First(&Order{}, 100).Error
+		require.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+		r.AssertCount(logger.Error, 1)
+		r.AssertMessageContains(logger.Error, "record not found")
+
+		r.Reset()
+		l.IgnoreRecordNotFoundError(true)
+		db.First(&Order{}, 100)
+		r.AssertCount(logger.Error, 0)
+		r.AssertCount(logger.Info, 1)
+	})
+
+	t.Run("no rows affected", func(t *testing.T) {
+		r.Reset()
+		l.FlagNoRowsAffected(logger.Warn)
+		defer l.FlagNoRowsAffected(logger.Silent)
+		require.NoError(t, db.Model(&Order{ID: 100}).Update("total", 20).Error)
+		require.NoError(t, db.Model(&Order{ID: 1}).Update("total", 20).Error)
+		r.AssertCount(logger.Warn, 1)
+		r.AssertFieldEquals(logger.Warn, "no_rows_affected", true)
+		r.AssertMessageContains(logger.Warn, "WHERE `id` = 100")
+	})
+
+	t.Run("ddl", func(t *testing.T) {
+		r.Reset()
+		l.DDLLevel(gormzerolog.Debug)
+		l.SetLogLevel(gormzerolog.Debug)
+		require.NoError(t, db.Migrator().DropTable(&Order{}))
+		r.AssertCount(logger.Info, 0)
+		r.AssertFieldEquals(gormzerolog.Debug, "ddl", true)
+		r.AssertMessageContains(gormzerolog.Debug, "DROP TABLE")
+	})
+}
+
+type User struct {
+	*gorm.Model
+	Name string
+}
+
+func TestTraceRecorderIntegration(t *testing.T) {
+	r := gormzerologtest.NewTraceRecorder()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: r})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&User{}))
+
+	r.Reset()
+	require.NoError(t, db.Create(&User{Name: "user1"}).Error)
+	require.NoError(t, db.Where("name = ?", "user1").First(&User{}).Error)
+	require.Error(t, db.Where("name = ?", "user2").First(&User{}).Error)
+
+	require.Len(t, r.Statements(), 3)
+	inserts := r.SQLContaining("INSERT INTO `users`")
+	require.Len(t, inserts, 1)
+	require.Equal(t, int64(1), inserts[0].Rows)
+	require.Len(t, r.SQLContaining(`name = "user1"`), 1)
+	require.Equal(t, []error{gorm.ErrRecordNotFound}, r.Errors())
+}
+
+func TestStatementMetadataIntegration(t *testing.T) {
+	l, r := gormzerologtest.Capture(t)
+	l.DisableBanner(true)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+	require.NoError(t, db.AutoMigrate(&User{}))
+
+	user := &User{Name: "user1"}
+	// the user is soft deleted with UPDATE, but the operation is still delete
+	for _, tc := range []struct {
+		name      string
+		run       func() error
+		operation string
+	}{
+		{"create", func() error { return db.Create(user).Error }, "insert"},
+		{"find", func() error { return db.Find(&[]User{}).Error }, "select"},
+		{"update", func() error { return db.Model(user).Update("name", "user2").Error }, "update"},
+		{"delete", func() error { return db.Delete(user).Error }, "delete"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r.Reset()
+			require.NoError(t, tc.run())
+			r.AssertCount(logger.Info, 1)
+			r.AssertFieldEquals(logger.Info, "model", "User")
+			r.AssertFieldEquals(logger.Info, "table", "users")
+			r.AssertFieldEquals(logger.Info, "operation", tc.operation)
+		})
+	}
+
+	t.Run("raw", func(t *testing.T) {
+		r.Reset()
+		require.NoError(t, db.Exec("DELETE FROM users WHERE name = ?", "user3").Error)
+		r.AssertFieldEquals(logger.Info, "operation", "delete")
+		r.AssertFieldEquals(logger.Info, "table", "users")
+		for _, e := range r.Level(logger.Info) {
+			require.NotContains(t, e.Fields, "model")
+		}
+	})
+}
+
+type Invoice struct {
+	ID     uint
+	Amount int
+}
+
+func (i *Invoice) BeforeCreate(*gorm.DB) error {
+	time.Sleep(time.Millisecond * 20)
+	return nil
+}
+
+func TestPhaseTimesIntegration(t *testing.T) {
+	l, r := gormzerologtest.Capture(t)
+	l.DisableBanner(true)
+	l.SlowThreshold(time.Millisecond * 10)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+	require.NoError(t, db.AutoMigrate(&Invoice{}))
+
+	r.Reset()
+	require.NoError(t, db.Create(&Invoice{Amount: 10}).Error)
+	warns := r.Level(logger.Warn)
+	require.Len(t, warns, 1)
+	dbTime, ok := warns[0].Fields["db_time"].(time.Duration)
+	require.True(t, ok)
+	callbacksTime, ok := warns[0].Fields["callbacks_time"].(time.Duration)
+	require.True(t, ok)
+	require.GreaterOrEqual(t, callbacksTime, time.Millisecond*20)
+	require.Less(t, dbTime, callbacksTime)
+	for _, e := range r.Level(logger.Info) {
+		require.NotContains(t, e.Fields, "db_time")
+	}
+
+	r.Reset()
+	l.LogPhaseTimesOnAll(true)
+	require.NoError(t, db.Find(&[]Invoice{}).Error)
+	r.AssertCount(logger.Info, 1)
+	require.Contains(t, r.Level(logger.Info)[0].Fields, "db_time")
+	require.Contains(t, r.Level(logger.Info)[0].Fields, "callbacks_time")
+}
+
+func TestDryRunIntegration(t *testing.T) {
+	l, r := gormzerologtest.Capture(t)
+	l.DisableBanner(true)
+	l.SetLogLevel(logger.Info)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+	require.NoError(t, db.AutoMigrate(&User{}))
+
+	r.Reset()
+	require.NoError(t, db.Session(&gorm.Session{DryRun: true}).Create(&User{Name: "dry"}).Error)
+	r.AssertCount(logger.Info, 1)
+	r.AssertFieldEquals(logger.Info, "dry_run", true)
+
+	r.Reset()
+	require.NoError(t, db.Create(&User{Name: "real"}).Error)
+	r.AssertCount(logger.Info, 1)
+	require.NotContains(t, r.Level(logger.Info)[0].Fields, "dry_run")
+
+	r.Reset()
+	l.DryRunLevel(gormzerolog.Debug)
+	require.NoError(t, db.Session(&gorm.Session{DryRun: true}).Create(&User{Name: "dry"}).Error)
+	r.AssertCount(logger.Info, 0)
+	r.AssertCount(gormzerolog.Debug, 0)
+
+	r.Reset()
+	l.SetLogLevel(gormzerolog.Debug)
+	require.NoError(t, db.Session(&gorm.Session{DryRun: true}).Create(&User{Name: "dry"}).Error)
+	r.AssertFieldEquals(gormzerolog.Debug, "dry_run", true)
+
+	var count int64
+	require.NoError(t, db.Model(&User{}).Count(&count).Error)
+	require.Equal(t, int64(1), count)
+}
+
+func TestInTransactionIntegration(t *testing.T) {
+	l, r := gormzerologtest.Capture(t)
+	l.DisableBanner(true)
+	l.SetLogLevel(logger.Info)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+	require.NoError(t, db.AutoMigrate(&User{}))
+
+	assertInTx := func(t *testing.T, n int) {
+		t.Helper()
+		infos := r.Level(logger.Info)
+		require.Len(t, infos, n)
+		for _, e := range infos {
+			require.Equal(t, true, e.Fields["in_tx"], e.Message)
+		}
+	}
+
+	t.Run("closure", func(t *testing.T) {
+		r.Reset()
+		require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&User{Name: "closure"}).Error
+		}))
+		assertInTx(t, 1)
+	})
+
+	t.Run("manual", func(t *testing.T) {
+		r.Reset()
+		tx := db.Begin()
+		require.NoError(t, tx.Create(&User{Name: "manual"}).Error)
+		require.NoError(t, tx.Find(&[]User{}).Error)
+		require.NoError(t, tx.Commit().Error)
+		assertInTx(t, 2)
+	})
+
+	t.Run("savepoint", func(t *testing.T) {
+		r.Reset()
+		require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+			require.NoError(t, tx.SavePoint("sp").Error)
+			require.NoError(t, tx.Create(&User{Name: "savepoint"}).Error)
+			require.NoError(t, tx.RollbackTo("sp").Error)
+			return tx.Transaction(func(tx *gorm.DB) error {
+				return tx.Create(&User{Name: "nested"}).Error
+			})
+		}))
+		// SAVEPOINT, ROLLBACK TO and the nested transaction savepoints are traced too
+		infos := r.Level(logger.Info)
+		require.NotEmpty(t, infos)
+		for _, e := range infos {
+			require.Equal(t, true, e.Fields["in_tx"], e.Message)
+		}
+	})
+
+	t.Run("sequence", func(t *testing.T) {
+		r.Reset()
+		require.NoError(t, l.Transaction(context.Background(), db, func(tx *gorm.DB) error {
+			require.NoError(t, tx.Create(&User{Name: "seq1"}).Error)
+			return tx.Create(&User{Name: "seq2"}).Error
+		}))
+		assertInTx(t, 2)
+		r.AssertFieldEquals(logger.Info, "tx_seq", int64(1))
+		r.AssertFieldEquals(logger.Info, "tx_seq", int64(2))
+	})
+
+	t.Run("outside", func(t *testing.T) {
+		r.Reset()
+		require.NoError(t, db.Create(&User{Name: "outside"}).Error)
+		r.AssertCount(logger.Info, 1)
+		require.NotContains(t, r.Level(logger.Info)[0].Fields, "in_tx")
+	})
+}
+
+type Translation struct {
+	Key   string `gorm:"primaryKey"`
+	Lang  string `gorm:"primaryKey"`
+	Value string
+}
+
+func TestAuditPrimaryKeysIntegration(t *testing.T) {
+	l, r := gormzerologtest.Capture(t)
+	l.DisableBanner(true)
+	l.SetLogLevel(logger.Info)
+	l.AuditPrimaryKeys(2)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+	require.NoError(t, db.AutoMigrate(&Order{}, &Translation{}))
+
+	t.Run("single", func(t *testing.T) {
+		r.Reset()
+		order := &Order{Total: 1}
+		require.NoError(t, db.Create(order).Error)
+		r.AssertFieldEquals(logger.Info, "pk", []any{order.ID})
+		r.Reset()
+		require.NoError(t, db.Model(order).Update("total", 2).Error)
+		r.AssertFieldEquals(logger.Info, "pk", []any{order.ID})
+		r.Reset()
+		require.NoError(t, db.Delete(order).Error)
+		r.AssertFieldEquals(logger.Info, "pk", []any{order.ID})
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		r.Reset()
+		orders := []Order{{Total: 1}, {Total: 2}, {Total: 3}}
+		require.NoError(t, db.Create(&orders).Error)
+		r.AssertFieldEquals(logger.Info, "pk", []any{orders[0].ID, orders[1].ID})
+		r.AssertFieldEquals(logger.Info, "pk_truncated", true)
+	})
+
+	t.Run("composite", func(t *testing.T) {
+		r.Reset()
+		require.NoError(t, db.Create(&Translation{Key: "hello", Lang: "en", Value: "Hello"}).Error)
+		r.AssertFieldEquals(logger.Info, "pk", []any{map[string]any{"key": "hello", "lang": "en"}})
+	})
+
+	t.Run("zero keys", func(t *testing.T) {
+		r.Reset()
+		require.NoError(t, db.Where("total > ?", 0).Delete(&Order{}).Error)
+		r.AssertCount(logger.Info, 1)
+		require.NotContains(t, r.Level(logger.Info)[0].Fields, "pk")
+	})
+
+	t.Run("reads", func(t *testing.T) {
+		r.Reset()
+		require.NoError(t, db.Find(&[]Translation{}).Error)
+		r.AssertCount(logger.Info, 1)
+		require.NotContains(t, r.Level(logger.Info)[0].Fields, "pk")
+	})
+}
+
+func TestParamCountIntegration(t *testing.T) {
+	l, r := gormzerologtest.Capture(t)
+	l.DisableBanner(true)
+	l.SetLogLevel(logger.Info)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+	require.NoError(t, db.AutoMigrate(&User{}))
+
+	r.Reset()
+	require.NoError(t, db.Where("name IN ?", []string{"a", "b", "c"}).Find(&[]User{}).Error)
+	r.AssertFieldEquals(logger.Info, "param_count", 3)
+
+	r.Reset()
+	require.NoError(t, db.Exec("DELETE FROM users").Error)
+	r.AssertFieldEquals(logger.Info, "param_count", 0)
+
+	r.Reset()
+	l.ParameterizedQueries(true)
+	require.NoError(t, db.Where("name IN ?", []string{"a", "b", "c"}).Find(&[]User{}).Error)
+	r.AssertFieldEquals(logger.Info, "param_count", 3)
+	r.AssertMessageContains(logger.Info, "IN (?,?,?)")
+}
+
+// recordingPool is a connection pool recording statements sent to the database.
+type recordingPool struct {
+	*sql.DB
+	mu         sync.Mutex
+	statements []string
+}
+
+func (p *recordingPool) record(query string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.statements = append(p.statements, query)
+}
+
+func (p *recordingPool) last() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.statements[len(p.statements)-1]
+}
+
+func (p *recordingPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	p.record(query)
+	return p.DB.ExecContext(ctx, query, args...)
+}
+
+func (p *recordingPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	p.record(query)
+	return p.DB.QueryContext(ctx, query, args...)
+}
+
+func (p *recordingPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	p.record(query)
+	return p.DB.QueryRowContext(ctx, query, args...)
+}
+
+type routeKey struct{}
+
+func TestSQLCommentsIntegration(t *testing.T) {
+	open := func(t *testing.T, config *gorm.Config) (*gorm.DB, *recordingPool) {
+		conn, err := sql.Open(sqlite.DriverName, ":memory:")
+		require.NoError(t, err)
+		conn.SetMaxOpenConns(1)
+		t.Cleanup(func() { conn.Close() })
+		pool := &recordingPool{DB: conn}
+		config.SkipDefaultTransaction = true
+		db, err := gorm.Open(sqlite.Dialector{Conn: pool}, config)
+		require.NoError(t, err)
+		return db, pool
+	}
+
+	comments := gormzerolog.SQLComments{
+		Static: map[string]string{"app": "svc"},
+		Context: map[string]func(context.Context) string{
+			"route": func(ctx context.Context) string {
+				route, _ := ctx.Value(routeKey{}).(string)
+				return route
+			},
+		},
+	}
+
+	ctx := context.WithValue(context.Background(), routeKey{}, "/orders/{id}")
+	const comment = "/*app='svc',route='%2Forders%2F%7Bid%7D'*/"
+	for _, strip := range []bool{false, true} {
+		t.Run(map[bool]string{false: "preserve", true: "strip"}[strip], func(t *testing.T) {
+			l, r := gormzerologtest.Capture(t)
+			l.DisableBanner(true)
+			comments.Strip = strip
+			l.CommentSQL(comments)
+			db, pool := open(t, &gorm.Config{})
+			require.NoError(t, l.Apply(db))
+			require.NoError(t, db.AutoMigrate(&Order{}))
+			db = db.WithContext(ctx)
+
+			for _, statement := range []func() error{
+				func() error { return db.Create(&Order{Total: 10}).Error },
+				func() error { return db.Where("total = ?", 10).Find(&[]Order{}).Error },
+				func() error { return db.Model(&Order{}).Where("total = ?", 10).Update("total", 20).Error },
+				func() error { return db.Where("total = ?", 20).Delete(&Order{}).Error },
+				func() error { return db.Exec("DELETE FROM orders WHERE total = ?;", 30).Error },
+				func() error {
+					var n int
+					return db.Raw("SELECT count(*) FROM orders").Row().Scan(&n)
+				},
+			} {
+				r.Reset()
+				require.NoError(t, statement())
+				sent := pool.last()
+				assert.Contains(t, sent, " "+comment, sent)
+				assert.NotContains(t, sent, "10", "parameters are never included")
+				logged := r.Level(logger.Info)
+				require.Len(t, logged, 1)
+				assert.Equal(t, !strip, strings.Contains(logged[0].Message, comment), logged[0].Message)
+			}
+
+			assert.True(t, strings.HasSuffix(pool.last(), "FROM orders "+comment))
+			assert.Contains(t, pool.statements[len(pool.statements)-2], "total = ? "+comment+";")
+
+			r.Reset()
+			require.NoError(t, db.Exec("DELETE FROM orders /* own comment */").Error)
+			assert.Equal(t, "DELETE FROM orders /* own comment */", pool.last())
+
+			sqlStr := db.ToSQL(func(tx *gorm.DB) *gorm.DB { return tx.Find(&[]Order{}) })
+			assert.NotContains(t, sqlStr, comment, "dry run")
+		})
+	}
+
+	t.Run("prepared statements", func(t *testing.T) {
+		l, r := gormzerologtest.Capture(t)
+		l.DisableBanner(true)
+		l.CommentSQL(comments)
+		db, _ := open(t, &gorm.Config{PrepareStmt: true})
+		require.NoError(t, l.Apply(db))
+		require.NoError(t, db.AutoMigrate(&Order{}))
+		r.Reset()
+		require.NoError(t, db.WithContext(ctx).Find(&[]Order{}).Error)
+		require.Len(t, r.Level(logger.Info), 1)
+		assert.NotContains(t, r.Level(logger.Info)[0].Message, "/*")
+	})
+}
+
+func TestLogBeforeExecutionIntegration(t *testing.T) {
+	l, r := gormzerologtest.Capture(t)
+	l.DisableBanner(true)
+	l.SQLPlacement(gormzerolog.Inline)
+	l.LogBeforeExecution(gormzerolog.Debug)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+	require.NoError(t, db.AutoMigrate(&Order{}, &User{}))
+
+	// assertPairs checks that every statement is logged once before
+	// its execution and its trace events carry the same ID.
+	assertPairs := func(t *testing.T, statements int) {
+		t.Helper()
+		var last string
+		traced := map[string]bool{}
+		for _, e := range r.Entries() {
+			id, _ := e.Fields["stmt_id"].(string)
+			require.NotEmpty(t, id, e)
+			if e.Message == "executing statement" {
+				require.True(t, last == "" || traced[last], "statement %s has no trace", last)
+				require.NotContains(t, traced, id)
+				assert.Equal(t, gormzerolog.Debug, e.Level)
+				assert.NotEmpty(t, e.Fields["sql"])
+				last = id
+				traced[id] = false
+				continue
+			}
+
+			require.Equal(t, last, id, e)
+			traced[id] = true
+		}
+
+		assert.Len(t, traced, statements)
+		assert.True(t, traced[last])
+	}
+
+	r.Reset()
+	order := &Order{Total: 10}
+	require.NoError(t, db.Create(order).Error)
+	require.NoError(t, db.Find(&[]Order{}).Error)
+	require.NoError(t, db.Model(order).Update("total", 20).Error)
+	require.NoError(t, db.Exec("UPDATE orders SET total = ?", 30).Error)
+	require.NoError(t, db.Delete(order).Error)
+	require.Error(t, db.Table("missing").Find(&[]Order{}).Error)
+	assertPairs(t, 6)
+
+	entries := r.Entries()
+	assert.Equal(t, "insert", entries[0].Fields["operation"])
+	assert.Equal(t, "orders", entries[0].Fields["table"])
+	assert.Equal(t, "UPDATE orders SET total = ?", entries[6].Fields["sql"], "parameter values should not be logged")
+	assert.Equal(t, logger.Error, entries[11].Level)
+	assert.NotEqual(t, entries[0].Fields["stmt_id"], entries[2].Fields["stmt_id"])
+
+	t.Run("filters", func(t *testing.T) {
+		r.Reset()
+		l.OnlyLogMatching(gormzerolog.MatchTables("orders"))
+		defer l.OnlyLogMatching()
+		require.NoError(t, db.Find(&[]User{}).Error)
+		require.NoError(t, db.Find(&[]Order{}).Error)
+		assertPairs(t, 1)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		r.Reset()
+		l.LogBeforeExecution(logger.Silent)
+		require.NoError(t, db.Find(&[]Order{}).Error)
+		r.AssertCount(logger.Info, 1)
+		r.AssertCount(gormzerolog.Debug, 0)
+		assert.NotContains(t, r.Entries()[0].Fields, "stmt_id")
+	})
+}
+
+// blockingPool is a connection pool holding queries until they are released,
+// which makes them deliberately slow.
+type blockingPool struct {
+	*sql.DB
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return p.DB.QueryContext(ctx, query, args...)
+}
+
+func TestWatchInFlightIntegration(t *testing.T) {
+	l, r := gormzerologtest.Capture(t)
+	l.DisableBanner(true)
+	l.SQLPlacement(gormzerolog.Inline)
+	l.SlowThreshold(time.Second)
+	l.WatchInFlight(true)
+	clock := gormzerologtest.NewClock(time.Now())
+	l = l.WithClock(clock)
+
+	conn, err := sql.Open(sqlite.DriverName, ":memory:")
+	require.NoError(t, err)
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+	pool := &blockingPool{DB: conn, started: make(chan struct{}), release: make(chan struct{})}
+	db, err := gorm.Open(sqlite.Dialector{Conn: pool}, &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+	require.NoError(t, db.AutoMigrate(&Order{}))
+
+	inFlight := func() []gormzerologtest.Entry {
+		var entries []gormzerologtest.Entry
+		for _, e := range r.Entries() {
+			if e.Message == "statement still running" {
+				entries = append(entries, e)
+			}
+		}
+
+		return entries
+	}
+
+	t.Run("slow", func(t *testing.T) {
+		r.Reset()
+		done := make(chan error)
+		go func() { done <- db.Find(&[]Order{}).Error }()
+		<-pool.started
+		clock.Advance(time.Second - time.Millisecond)
+		assert.Empty(t, inFlight())
+		clock.Advance(time.Millisecond)
+		require.Len(t, inFlight(), 1, "the warning should be logged while the statement is running")
+		clock.Advance(time.Second)
+		assert.Len(t, inFlight(), 1, "the warning should be logged once")
+
+		close(pool.release)
+		defer func() { pool.release = make(chan struct{}) }()
+		require.NoError(t, <-done)
+		entries := r.Entries()
+		require.Len(t, entries, 3)
+		e := entries[0]
+		assert.Equal(t, logger.Warn, e.Level)
+		assert.Equal(t, true, e.Fields["in_flight"])
+		assert.Equal(t, time.Second, e.Fields["elapsed"])
+		assert.Equal(t, "SELECT * FROM `orders`", e.Fields["sql"])
+		assert.Equal(t, "orders", e.Fields["table"])
+		assert.Equal(t, "select", e.Fields["operation"])
+		assert.NotEmpty(t, e.Fields["stmt_id"])
+		for _, trace := range entries[1:] {
+			assert.Equal(t, e.Fields["stmt_id"], trace.Fields["stmt_id"])
+		}
+
+		assert.Zero(t, clock.Timers())
+	})
+
+	t.Run("fast", func(t *testing.T) {
+		r.Reset()
+		close(pool.release)
+		defer func() { pool.release = make(chan struct{}) }()
+		go func() {
+			for range pool.started {
+			}
+		}()
+		defer close(pool.started)
+		require.NoError(t, db.Find(&[]Order{}).Error)
+		assert.Zero(t, clock.Timers(), "the timer should be stopped on completion")
+		clock.Advance(time.Hour)
+		assert.Empty(t, inFlight())
+		r.AssertCount(logger.Info, 1)
+	})
+}
+
+func TestWatchInFlightRaceIntegration(t *testing.T) {
+	l, r := gormzerologtest.Capture(t)
+	l.DisableBanner(true)
+	l.SlowThreshold(time.Microsecond)
+	l.WatchInFlight(true)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+	require.NoError(t, db.AutoMigrate(&Order{}))
+
+	r.Reset()
+	for i := 0; i < 100; i++ {
+		require.NoError(t, db.Find(&[]Order{}).Error)
+	}
+
+	// the timers racing with completion never log after the trace
+	traced := map[any]bool{}
+	for _, e := range r.Entries() {
+		id := e.Fields["stmt_id"]
+		if e.Message == "statement still running" {
+			assert.False(t, traced[id], "warning of %v logged after its trace", id)
+			continue
+		}
+
+		traced[id] = true
+	}
+
+	assert.Len(t, traced, 100)
+}
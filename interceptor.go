@@ -0,0 +1,30 @@
+package gormzerolog
+
+import (
+	"context"
+	"slices"
+)
+
+// TraceInterceptor is called for every traced statement before the record is
+// emitted. It may modify the record, the changes are visible in the logged
+// events. Returning false drops the record.
+type TraceInterceptor func(ctx context.Context, rec *TraceRecord) (keep bool)
+
+// Use adds interceptors of trace records. Interceptors are executed in
+// the order they were added, after the built-in enrichment and checks of
+// the record, so they see its final fields. Once an interceptor drops
+// the record, the rest are not executed.
+func (l *GormLogger) Use(interceptors ...TraceInterceptor) {
+	l.interceptors = append(slices.Clip(l.interceptors), interceptors...)
+}
+
+// intercept runs the interceptors and reports whether the record is kept.
+func (l *GormLogger) intercept(ctx context.Context, rec *TraceRecord) bool {
+	for _, interceptor := range l.interceptors {
+		if !interceptor(ctx, rec) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,91 @@
+package gormzerolog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormLoggerInterceptors(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory)
+	l.WarnLargeInLists(2)
+	trace := func(sql string) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	var calls []string
+	l.Use(
+		func(_ context.Context, rec *TraceRecord) bool {
+			calls = append(calls, "poller")
+			return !strings.Contains(rec.SQL, "metrics")
+		},
+		func(_ context.Context, rec *TraceRecord) bool {
+			calls = append(calls, "shards")
+			assert.Equal("select", rec.Operation)
+			if strings.HasPrefix(rec.Table, "orders_") {
+				rec.Table = "orders"
+				rec.SQL = strings.ReplaceAll(rec.SQL, "orders_7", "orders")
+			}
+
+			return true
+		},
+	)
+
+	trace("SELECT * FROM metrics")
+	assert.Equal([]string{"poller"}, calls)
+	assert.Empty(infoSink.events)
+
+	calls = nil
+	trace("SELECT * FROM orders_7 WHERE id = 1")
+	assert.Equal([]string{"poller", "shards"}, calls)
+	require.Len(t, infoSink.events, 1)
+	assert.Equal("orders", infoSink.events[0].added["table"])
+	assert.Contains(infoSink.events[0].msg, "SELECT * FROM orders WHERE id = 1")
+
+	l.Use(func(_ context.Context, rec *TraceRecord) bool {
+		assert.Equal(3, rec.InListSize)
+		return true
+	})
+	trace("SELECT * FROM users WHERE id IN (1,2,3)")
+	require.Len(t, warnSink.events, 1)
+	assert.Equal("users", warnSink.events[0].added["table"])
+}
+
+func TestGormLoggerInterceptorsSeeErrorFields(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	nop := func() Event { return &testingEvent{} }
+	l := NewGormLogger().WithInfo(nop).WithError(sink.factory)
+	var seen []TraceRecord
+	l.Use(func(_ context.Context, rec *TraceRecord) bool {
+		seen = append(seen, *rec)
+		return true
+	})
+	trace := func(err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "INSERT INTO users (id) VALUES (1)", 0 }, err)
+	}
+
+	trace(fmt.Errorf("create: %w", gorm.ErrDuplicatedKey))
+	require.Len(t, seen, 1)
+	assert.Equal("duplicated_key", seen[0].ErrorKind)
+	require.Len(t, sink.events, 1)
+	assert.Equal("duplicated_key", sink.events[0].added["error_kind"])
+
+	trace(&pgError{Code: "23505", ConstraintName: "users_email_key"})
+	trace(&MySQLError{Number: 1213, Message: "Deadlock found"})
+	require.Len(t, seen, 3)
+	assert.Equal("23505", seen[1].SQLState)
+	assert.Equal("users_email_key", seen[1].Constraint)
+	assert.False(seen[1].Retryable)
+	assert.Equal(uint64(1213), seen[2].MySQLErrno)
+	assert.True(seen[2].Retryable)
+}
@@ -0,0 +1,66 @@
+package gormzerolog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var defaultLatencyLabels = []string{"fast", "normal", "slow", "very_slow"}
+
+type latencyBuckets struct {
+	bounds []time.Duration
+	labels []string
+}
+
+// label returns the label of the first bucket with the bound not less than
+// the elapsed time, or the last label if the elapsed time exceeds all bounds.
+func (b *latencyBuckets) label(elapsed time.Duration) string {
+	for i, bound := range b.bounds {
+		if elapsed <= bound {
+			return b.labels[i]
+		}
+	}
+
+	return b.labels[len(b.labels)-1]
+}
+
+// SetLatencyBuckets enables the latency_bucket field on every trace. Bounds are
+// strictly increasing inclusive upper bounds of the buckets: a trace with
+// the elapsed time equal to a bound falls into the bucket of the bound.
+// There has to be one label more than bounds, the last one is used for traces
+// exceeding all bounds. If labels are omitted, three bounds are labeled
+// fast, normal, slow and very_slow. Empty bounds disable the field.
+func (l *GormLogger) SetLatencyBuckets(bounds []time.Duration, labels ...string) error {
+	if len(bounds) == 0 {
+		l.latencyBuckets = nil
+		return nil
+	}
+
+	if len(labels) == 0 && len(bounds) == len(defaultLatencyLabels)-1 {
+		labels = defaultLatencyLabels
+	}
+
+	if len(labels) != len(bounds)+1 {
+		return fmt.Errorf("%d latency bounds require %d labels, got %d", len(bounds), len(bounds)+1, len(labels))
+	}
+
+	for i := range bounds {
+		if i > 0 && bounds[i] <= bounds[i-1] {
+			return fmt.Errorf("latency bounds are not strictly increasing: %v after %v", bounds[i], bounds[i-1])
+		}
+	}
+
+	for _, label := range labels {
+		if label == "" {
+			return errors.New("latency bucket label is empty")
+		}
+	}
+
+	l.latencyBuckets = &latencyBuckets{
+		bounds: append([]time.Duration(nil), bounds...),
+		labels: append([]string(nil), labels...),
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package gormzerolog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormLoggerLatencyBuckets(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	infoSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithClock(clock)
+	l.SlowThreshold(0)
+	require.NoError(t, l.SetLatencyBuckets([]time.Duration{time.Millisecond * 10, time.Millisecond * 100, time.Second}))
+
+	for _, tc := range []struct {
+		elapsed time.Duration
+		label   string
+	}{
+		{0, "fast"},
+		{time.Millisecond * 10, "fast"},
+		{time.Millisecond*10 + 1, "normal"},
+		{time.Millisecond * 100, "normal"},
+		{time.Millisecond * 500, "slow"},
+		{time.Second, "slow"},
+		{time.Second + 1, "very_slow"},
+		{time.Hour, "very_slow"},
+	} {
+		infoSink.events = nil
+		l.Trace(context.Background(), clock.Now().Add(-tc.elapsed), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		require.Len(t, infoSink.events, 1)
+		assert.Equal(tc.label, infoSink.events[0].added["latency_bucket"], tc.elapsed.String())
+	}
+
+	require.NoError(t, l.SetLatencyBuckets([]time.Duration{time.Millisecond}, "ok", "late"))
+	infoSink.events = nil
+	l.Trace(context.Background(), clock.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	assert.Equal("late", infoSink.events[0].added["latency_bucket"])
+
+	require.NoError(t, l.SetLatencyBuckets(nil))
+	infoSink.events = nil
+	l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	assert.NotContains(infoSink.events[0].added, "latency_bucket")
+}
+
+func TestGormLoggerLatencyBucketsInvalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		bounds []time.Duration
+		labels []string
+	}{
+		"not increasing":    {[]time.Duration{time.Second, time.Millisecond}, []string{"a", "b", "c"}},
+		"equal bounds":      {[]time.Duration{time.Second, time.Second}, []string{"a", "b", "c"}},
+		"too few labels":    {[]time.Duration{time.Second}, []string{"a"}},
+		"too many labels":   {[]time.Duration{time.Second}, []string{"a", "b", "c"}},
+		"no default labels": {[]time.Duration{time.Second, time.Minute}, nil},
+		"empty label":       {[]time.Duration{time.Second}, []string{"a", ""}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := NewGormLogger()
+			assert.Error(t, l.SetLatencyBuckets(tc.bounds, tc.labels...))
+			assert.Nil(t, l.latencyBuckets)
+		})
+	}
+}
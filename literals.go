@@ -0,0 +1,93 @@
+package gormzerolog
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Literals are human-facing texts logged by the logger. Tokens in braces are
+// replaced with values when the text is logged.
+type Literals struct {
+	// SlowQuery is a notice of slow query messages. The {threshold} token is
+	// replaced with the slow threshold. Default is "SLOW SQL >= {threshold}".
+	SlowQuery string
+	// UnknownRows is logged instead of the number of rows when it is unknown.
+	// Default is "-".
+	UnknownRows string
+	// Banner is a message logged when the logger is attached to a database.
+	// Default is "gorm zerolog logger attached".
+	Banner string
+	// OverloadSuspended is a message logged when trace logging is suspended.
+	// The {rate} and {limit} tokens are replaced with the trace rate and
+	// the MaxRate of the overload protection.
+	OverloadSuspended string
+	// OverloadResumed is a message logged when trace logging is resumed.
+	// The {rate} and {limit} tokens are replaced with the trace rate and
+	// the RecoverRate of the overload protection.
+	OverloadResumed string
+	// SlowestReport is a message of the slowest statements report.
+	// Default is "slowest statements".
+	SlowestReport string
+	// DroppedEvents is a warning about trace records dropped by the
+	// backpressure policy. The {count} token is replaced with their number.
+	// Default is "dropped {count} trace events".
+	DroppedEvents string
+	// BeforeExecution is a message of statements logged by LogBeforeExecution.
+	// Default is "executing statement".
+	BeforeExecution string
+	// InFlight is a warning about a statement still running after the slow
+	// threshold, logged by WatchInFlight. Default is "statement still running".
+	InFlight string
+	// Audit is a message of write statements logged by WithAudit.
+	// Default is "write statement".
+	Audit string
+}
+
+var defaultLiterals = Literals{
+	SlowQuery:         "SLOW SQL >= {threshold}",
+	UnknownRows:       "-",
+	Banner:            "gorm zerolog logger attached",
+	OverloadSuspended: "trace logging suspended: {rate} traces/s exceeded {limit} traces/s",
+	OverloadResumed:   "trace logging resumed: {rate} traces/s dropped below {limit} traces/s",
+	SlowestReport:     "slowest statements",
+	DroppedEvents:     "dropped {count} trace events",
+	BeforeExecution:   "executing statement",
+	InFlight:          "statement still running",
+	Audit:             "write statement",
+}
+
+// SetLiterals sets human-facing texts of the logger.
+// Empty texts keep their current values.
+func (l *GormLogger) SetLiterals(literals Literals) {
+	for _, p := range []struct {
+		dst *string
+		src string
+	}{
+		{&l.literals.SlowQuery, literals.SlowQuery},
+		{&l.literals.UnknownRows, literals.UnknownRows},
+		{&l.literals.Banner, literals.Banner},
+		{&l.literals.OverloadSuspended, literals.OverloadSuspended},
+		{&l.literals.OverloadResumed, literals.OverloadResumed},
+		{&l.literals.SlowestReport, literals.SlowestReport},
+		{&l.literals.DroppedEvents, literals.DroppedEvents},
+		{&l.literals.BeforeExecution, literals.BeforeExecution},
+		{&l.literals.InFlight, literals.InFlight},
+		{&l.literals.Audit, literals.Audit},
+	} {
+		if p.src != "" {
+			*p.dst = p.src
+		}
+	}
+}
+
+func (l *GormLogger) slowQueryNotice(threshold time.Duration) string {
+	return strings.ReplaceAll(l.literals.SlowQuery, "{threshold}", threshold.String())
+}
+
+func overloadNotice(literal string, rate, limit float64) string {
+	return strings.NewReplacer(
+		"{rate}", strconv.FormatFloat(rate, 'f', 1, 64),
+		"{limit}", strconv.FormatFloat(limit, 'f', 1, 64),
+	).Replace(literal)
+}
@@ -0,0 +1,45 @@
+package gormzerolog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormLoggerLiterals(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithClock(clock)
+	l.SlowThreshold(time.Millisecond * 100)
+	l.SetLiterals(Literals{SlowQuery: "QUERY_SLOW (>{threshold})", UnknownRows: "n/a"})
+	l.SetLiterals(Literals{OverloadSuspended: "ausgesetzt bei {rate}/s, Grenze {limit}/s"})
+	assert.Equal(defaultLiterals.Banner, l.literals.Banner)
+
+	l.Trace(context.Background(), clock.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", -1 }, nil)
+	assert.Contains(warnSink.messages()[0], "QUERY_SLOW (>100ms)\n")
+	assert.Contains(warnSink.messages()[0], "[rows:n/a]")
+	assert.Contains(infoSink.messages()[0], "[rows:n/a]")
+
+	l.OverloadProtection(OverloadConfig{MaxRate: 1, Sustain: time.Second})
+	for i := 0; i < 30; i++ {
+		clock.Advance(time.Second / 10)
+		l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	assert.Regexp(`^ausgesetzt bei \d+\.\d/s, Grenze 1\.0/s$`, warnSink.messages()[1])
+}
+
+func TestGormLoggerLiteralsConsole(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewConsoleGormLogger(buf)
+	l.SetLiterals(Literals{SlowQuery: "QUERY_SLOW"})
+	l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	assert.Contains(t, buf.String(), "WRN")
+	assert.Contains(t, buf.String(), "QUERY_SLOW\n")
+	assert.NotContains(t, buf.String(), "SLOW SQL")
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"regexp"
-	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -15,15 +18,33 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-const (
-	traceErrMsg  = "%s %s\n[%.3fms] [rows:%v] %s"
-	traceWarnMsg = "%s %s\n[%.3fms] [rows:%v] %s"
-	traceInfoMsg = "%s\n[%.3fms] [rows:%v] %s"
-)
+// Debug is a log level more verbose than logger.Info. Events of this level
+// are logged only when the logger is in Debug mode.
+const Debug logger.LogLevel = logger.Info + 1
+
+// LevelName returns a lower case name of the log level.
+func LevelName(logLevel logger.LogLevel) string {
+	switch logLevel {
+	case logger.Silent:
+		return "silent"
+	case logger.Error:
+		return "error"
+	case logger.Warn:
+		return "warn"
+	case logger.Info:
+		return "info"
+	case Debug:
+		return "debug"
+	default:
+		return strconv.Itoa(int(logLevel))
+	}
+}
 
 // Event represents a proxy object between GORM Logger and zerolog.
 type Event interface {
 	Str(key, value string) Event
+	Any(key string, value any) Event
+	Dur(key string, d time.Duration) Event
 	Msgf(format string, v ...any)
 }
 
@@ -36,67 +57,378 @@ func (e *GormLoggerEvent) Str(key, value string) Event {
 	return e
 }
 
-func (e *GormLoggerEvent) Msgf(format string, v ...any) {
-	e.Event.Msgf(format, v...)
-}
-
-func newGormLoggerEventInfo() Event {
-	return &GormLoggerEvent{
-		Event: log.Info(),
+// Any adds the field with the value of any type, using a typed zerolog
+// method for common types.
+func (e *GormLoggerEvent) Any(key string, value any) Event {
+	switch v := value.(type) {
+	case string:
+		e.Event = e.Event.Str(key, v)
+	case bool:
+		e.Event = e.Event.Bool(key, v)
+	case int:
+		e.Event = e.Event.Int(key, v)
+	case int64:
+		e.Event = e.Event.Int64(key, v)
+	case uint64:
+		e.Event = e.Event.Uint64(key, v)
+	case float64:
+		e.Event = e.Event.Float64(key, v)
+	case time.Time:
+		e.Event = e.Event.Time(key, v)
+	case time.Duration:
+		e.Event = e.Event.Dur(key, v)
+	case error:
+		e.Event = e.Event.AnErr(key, v)
+	default:
+		e.Event = e.Event.Any(key, v)
 	}
+
+	return e
 }
 
-func newGormLoggerEventWarn() Event {
-	return &GormLoggerEvent{
-		Event: log.Warn(),
-	}
+// Dur adds the duration field, written in zerolog.DurationFieldUnit.
+func (e *GormLoggerEvent) Dur(key string, d time.Duration) Event {
+	e.Event = e.Event.Dur(key, d)
+	return e
 }
 
-func newGormLoggerEventError() Event {
-	return &GormLoggerEvent{
-		Event: log.Error(),
-	}
+func (e *GormLoggerEvent) Msgf(format string, v ...any) {
+	e.Event.Msgf(format, v...)
 }
 
 // GormLogger represents an logging object for handling GORM logs with zerolog.
+//
+// The logger is configured before it is used. Only SetLogLevel, SlowThreshold,
+// SetAdditionalData and UpdateLoggerContext are safe to call while logging;
+// the With* methods returning copies are safe to call at any time.
 type GormLogger struct {
-	logLevel                logger.LogLevel
+	settings                *settings
 	ignoreRecordNotFoundErr bool
-	slowThreshold           time.Duration
+	ignoredErrors           []error
+	singleEvent             bool
+	errorFilter             func(err error, sql string) bool
 	loggers                 map[logger.LogLevel]func() Event
+	sampler                 zerolog.Sampler
+	samplers                map[logger.LogLevel]zerolog.Sampler
+	async                   *asyncEmitter
+	txBufferSize            int
+	ddlLevel                logger.LogLevel
+	disableBanner           bool
+	overload                *overloadBreaker
+	levelData               map[logger.LogLevel]map[string]string
+	base                    *baseLogger
+	staticFields            map[string]any
+	dbInfo                  DatabaseInfo
+	skipCallerPackages      []string
+	callerStart             int
+	callerMax               int
+	stackDepth              int
+	callerPathElements      int
+	trimCallerModule        bool
+	disableCaller           bool
+	zerologCaller           bool
+	contextLoggers          bool
+	callerField             bool
+	callerFunc              bool
+	callerMarshaler         func(file string, line int, fn string) string
+	callerResolver          CallerResolver
+	clock                   Clock
+	templates               traceTemplates
+	sqlPlacement            Placement
+	sqlLevels               map[logger.LogLevel]struct{}
+	sqlLimit                int
+	queryFingerprints       bool
+	normalizedSQL           bool
+	parameterizedQueries    bool
+	literals                Literals
+	fieldNames              FieldNames
+	latencyBuckets          *latencyBuckets
+	rowsFields              rowsFieldNames
+	phaseTimesOnAll         bool
+	dryRunLevel             logger.LogLevel
+	auditPKLimit            int
+	audit                   *auditChannel
+	tenants                 *tenantConfig
+	inListLimit             int
+	unboundedTables         map[string]struct{}
+	unboundedLevel          logger.LogLevel
+	noRowsLevel             logger.LogLevel
+	cancelLevel             logger.LogLevel
+	errorLevelMapper        func(err error) logger.LogLevel
+	errorKinds              []errorKind
+	retryableBuilder        func() Event
+	slowBuilder             func() Event
+	rowsThreshold           int64
+	tableRowsThresholds     map[string]int64
+	rowsLevel               logger.LogLevel
+	deadlineLevel           logger.LogLevel
+	preExecLevel            logger.LogLevel
+	watchInFlight           bool
+	deadlineAllowed         []*regexp.Regexp
+	escalated               []statementMatcher
+	interceptors            []TraceInterceptor
+	matchers                []TraceMatcher
+	matchErrors             bool
+	firstSeen               *seenSet
+	sqlComments             *SQLComments
+	disableEvents           bool
+	metricsHook             MetricsHook
+	labelLimiter            *labelLimiter
+	slowest                 *slowestCollector
+	debug                   *debugCollector
+	strict                  *strictMode
+	errorSampler            *errorSampler
+	regressions             *regressionDetector
+	stats                   *loggerStats
+	fieldProviders          []func() map[string]any
+	contextExtractors       []func(ctx context.Context) map[string]any
+	spanExtractor           SpanExtractor
+	// fields is never modified in place, so it is safe for concurrent use.
+	fields map[string]string
 
+	// AdditionalData is a legacy string-only variant of AdditionalFields.
+	//
+	// Deprecated: use WithField, WithFields or SetAdditionalData instead. The map
+	// is read on every event, so modifying it while logging is a data race.
 	AdditionalData map[string]string
+	// AdditionalFields are added to every logged event with their JSON types
+	// preserved. They take precedence over string fields.
+	AdditionalFields map[string]any
+	// AdditionalDataFunc is called for every logged event. The returned
+	// fields take precedence over any other additional data.
+	AdditionalDataFunc func() map[string]string
 }
 
 // NewGormLogger creates a new GORM zerolog logger.
 func NewGormLogger() *GormLogger {
 	return &GormLogger{
-		logLevel:      logger.Info,
-		slowThreshold: time.Millisecond * 200,
-		loggers: map[logger.LogLevel]func() Event{
-			logger.Info:  newGormLoggerEventInfo,
-			logger.Warn:  newGormLoggerEventWarn,
-			logger.Error: newGormLoggerEventError,
-		},
+		settings:    newSettings(logger.Info, time.Millisecond*200),
+		ddlLevel:    logger.Info,
+		dryRunLevel: logger.Info,
+		rowsLevel:   logger.Warn,
+		cancelLevel: logger.Warn,
+		errorKinds:  defaultErrorKinds,
+		callerStart: 2,
+		callerMax:   15,
+		clock:       realClock{},
+		literals:    defaultLiterals,
+		fieldNames:  defaultFieldNames,
+		rowsFields:  defaultRowsFieldNames,
+		loggers:     map[logger.LogLevel]func() Event{},
+		base:        &baseLogger{},
+		stats:       &loggerStats{},
 	}
 }
 
-// WithInfo sets a logger builder for info level logging.
-func (l *GormLogger) WithInfo(info func() Event) *GormLogger {
-	l.loggers[logger.Info] = info
+// NewWithLogger creates a new GORM zerolog logger writing events with zl
+// instead of the global zerolog logger.
+func NewWithLogger(zl zerolog.Logger) *GormLogger {
+	l := NewGormLogger()
+	l.base.logger.Store(&zl)
 	return l
 }
 
-// WithWarn sets a logger builder for warn level logging.
+// clone returns a copy of the logger which doesn't share mutable state with it.
+// The deprecated AdditionalData map is snapshotted into the copy's fields.
+func (l *GormLogger) clone() *GormLogger {
+	c := *l
+	c.loggers = maps.Clone(l.loggers)
+	c.samplers = maps.Clone(l.samplers)
+	c.levelData = maps.Clone(l.levelData)
+	c.staticFields = maps.Clone(l.staticFields)
+	c.tableRowsThresholds = maps.Clone(l.tableRowsThresholds)
+	c.base = l.base.clone()
+	c.settings = l.settings.clone()
+	if len(l.AdditionalData) > 0 {
+		data := l.additionalData()
+		fields := maps.Clone(l.AdditionalData)
+		maps.DeleteFunc(fields, func(k, _ string) bool { return hasKey(data, k) })
+		maps.Copy(fields, l.fields)
+		c.fields = fields
+	}
+
+	c.AdditionalData = nil
+	return &c
+}
+
+// Clone returns a deep copy of the logger, including the event builders,
+// AdditionalData and AdditionalFields, so per-database or per-module variants
+// can be configured without affecting the original. Stats, collectors, the
+// asynchronous emitter and the strict mode are shared with the original.
+func (l *GormLogger) Clone() *GormLogger {
+	c := l.clone()
+	c.fields = l.fields
+	c.AdditionalData = maps.Clone(l.AdditionalData)
+	c.AdditionalFields = maps.Clone(l.AdditionalFields)
+	return c
+}
+
+// WithField returns a copy of the logger adding the field to every event.
+func (l *GormLogger) WithField(key, value string) *GormLogger {
+	return l.WithFields(map[string]string{key: value})
+}
+
+// WithFields returns a copy of the logger adding the fields to every event.
+// Fields set this way take precedence over AdditionalData.
+func (l *GormLogger) WithFields(fields map[string]string) *GormLogger {
+	c := l.clone()
+	merged := make(map[string]string, len(c.fields)+len(fields))
+	maps.Copy(merged, c.fields)
+	maps.Copy(merged, fields)
+	c.fields = merged
+	return c
+}
+
+// AddField adds the field to every event like AdditionalFields, written
+// natively by the built-in event builders for ints, bools, floats, times,
+// durations and errors. The AdditionalFields map is replaced, not modified.
+func (l *GormLogger) AddField(key string, value any) {
+	fields := make(map[string]any, len(l.AdditionalFields)+1)
+	maps.Copy(fields, l.AdditionalFields)
+	fields[key] = value
+	l.AdditionalFields = fields
+}
+
+// WithFieldProvider returns a copy of the logger calling the provider for
+// every event and adding the returned fields with their types preserved,
+// e.g. to attach values which change at runtime. The fields take precedence
+// over AdditionalFields and fields of providers added before.
+func (l *GormLogger) WithFieldProvider(provider func() map[string]any) *GormLogger {
+	c := l.clone()
+	c.fieldProviders = append(slices.Clip(l.fieldProviders), provider)
+	return c
+}
+
+// WithDebug returns a copy of the logger with a logger builder for debug
+// level logging.
+func (l *GormLogger) WithDebug(debug func() Event) *GormLogger {
+	return l.withBuilder(Debug, debug)
+}
+
+// WithInfo returns a copy of the logger with a logger builder for info level
+// logging.
+func (l *GormLogger) WithInfo(info func() Event) *GormLogger {
+	return l.withBuilder(logger.Info, info)
+}
+
+// WithWarn returns a copy of the logger with a logger builder for warn level
+// logging.
 func (l *GormLogger) WithWarn(warn func() Event) *GormLogger {
-	l.loggers[logger.Warn] = warn
-	return l
+	return l.withBuilder(logger.Warn, warn)
 }
 
-// WithError sets a logger builder for error level logging.
+// WithError returns a copy of the logger with a logger builder for error
+// level logging.
 func (l *GormLogger) WithError(err func() Event) *GormLogger {
-	l.loggers[logger.Error] = err
-	return l
+	return l.withBuilder(logger.Error, err)
+}
+
+// WithSlow returns a copy of the logger writing slow query traces with the
+// builder instead of the warn builder, e.g. to route slow SQL to a dedicated
+// logger, sampler or sink. Statements escalated with EscalateStatements are
+// still logged with the error builder. Nil builder disables the routing.
+func (l *GormLogger) WithSlow(slow func() Event) *GormLogger {
+	c := l.clone()
+	c.slowBuilder = slow
+	return c
+}
+
+// withBuilder returns a copy of the logger with the logger builder for the
+// level, so a base logger can be specialized without being modified.
+func (l *GormLogger) withBuilder(logLevel logger.LogLevel, f func() Event) *GormLogger {
+	c := l.clone()
+	if c.loggers == nil {
+		c.loggers = map[logger.LogLevel]func() Event{}
+	}
+
+	c.loggers[logLevel] = f
+	return c
+}
+
+// WithSampler returns a copy of the logger with a zerolog sampler applied by
+// the built-in event builders of all levels without a level sampler.
+func (l *GormLogger) WithSampler(s zerolog.Sampler) *GormLogger {
+	c := l.clone()
+	c.sampler = s
+	return c
+}
+
+// WithLevelSampler returns a copy of the logger with a zerolog sampler applied
+// by the built-in event builder of the given level. Nil sampler disables
+// sampling for the level.
+func (l *GormLogger) WithLevelSampler(logLevel logger.LogLevel, s zerolog.Sampler) *GormLogger {
+	c := l.clone()
+	if c.samplers == nil {
+		c.samplers = map[logger.LogLevel]zerolog.Sampler{}
+	}
+
+	c.samplers[logLevel] = s
+	return c
+}
+
+// WithStaticFields returns a copy of the logger adding fields which never
+// change during the process lifetime. The built-in event builders get the
+// fields in the context of the underlying zerolog logger, which is copied from
+// the global one at the moment of the call. Events of custom builders get the
+// fields attached one by one.
+func (l *GormLogger) WithStaticFields(fields map[string]string) *GormLogger {
+	typed := make(map[string]any, len(fields))
+	for k, v := range fields {
+		typed[k] = v
+	}
+
+	return l.withStaticFields(typed)
+}
+
+// withStaticFields is WithStaticFields for fields with their types preserved.
+func (l *GormLogger) withStaticFields(fields map[string]any) *GormLogger {
+	c := l.clone()
+	if c.staticFields == nil {
+		c.staticFields = map[string]any{}
+	}
+
+	for k, v := range fields {
+		c.staticFields[k] = v
+	}
+
+	c.UpdateLoggerContext(func(zc zerolog.Context) zerolog.Context {
+		for k, v := range fields {
+			zc = zc.Interface(k, v)
+		}
+
+		return zc
+	})
+	return c
+}
+
+// UpdateLoggerContext updates the context of the zerolog logger used by the
+// built-in event builders, e.g. to add a field known only after startup.
+// The logger is swapped atomically, so events already being built are not
+// affected. The global logger is copied on the first update. Custom event
+// builders are not affected.
+func (l *GormLogger) UpdateLoggerContext(update func(zerolog.Context) zerolog.Context) {
+	l.base.mu.Lock()
+	defer l.base.mu.Unlock()
+	zl := update(l.zerologLogger().With()).Logger()
+	l.base.logger.Store(&zl)
+}
+
+// SetAdditionalDataFor sets additional data added only to events of the given
+// level, taking precedence over AdditionalData and AdditionalFields. Slow query
+// traces are logged at warn level.
+func (l *GormLogger) SetAdditionalDataFor(logLevel logger.LogLevel, fields map[string]string) {
+	if l.levelData == nil {
+		l.levelData = map[logger.LogLevel]map[string]string{}
+	}
+
+	l.levelData[logLevel] = fields
+}
+
+// SingleEventPerQuery sets a flag for logging a single event per statement:
+// the info trace of a failed or slow statement is not logged after its error
+// or slow query event.
+func (l *GormLogger) SingleEventPerQuery(b bool) {
+	l.singleEvent = b
 }
 
 // IgnoreRecordNotFoundError sets a flag for ignoring ErrRecordNotFound error.
@@ -104,86 +436,896 @@ func (l *GormLogger) IgnoreRecordNotFoundError(b bool) {
 	l.ignoreRecordNotFoundErr = b
 }
 
-// LogMode sets a log level value.
+// IgnoreErrors adds errors which are ignored like ErrRecordNotFound with
+// IgnoreRecordNotFoundError, matched with errors.Is, e.g. expected lock
+// contention errors. Statements failed with them are traced as successful.
+func (l *GormLogger) IgnoreErrors(errs ...error) {
+	l.ignoredErrors = append(slices.Clip(l.ignoredErrors), errs...)
+}
+
+// LogMode returns a copy of the logger with the log level, like the default
+// GORM logger, so db.Session(&gorm.Session{...}).Debug() doesn't change the
+// level of other sessions. Use SetLogLevel to change the level in place.
 func (l *GormLogger) LogMode(logLevel logger.LogLevel) logger.Interface {
-	l.logLevel = logLevel
-	return l
+	c := l.clone()
+	c.SetLogLevel(logLevel)
+	return c
 }
 
-// SlowThreshold sets a slow threshold level value.
-func (l *GormLogger) SlowThreshold(slowThreshold time.Duration) {
-	l.slowThreshold = slowThreshold
+// DDLLevel sets a log level for traces of schema definition and migration
+// statements (CREATE, ALTER, DROP, etc.), which are logged at Info by default.
+// Errors and slow statements keep their levels.
+func (l *GormLogger) DDLLevel(logLevel logger.LogLevel) {
+	l.ddlLevel = logLevel
 }
 
-func (l *GormLogger) log(logLevel logger.LogLevel, msg string, data ...any) {
-	if l.logLevel >= logLevel {
-		if f, ok := l.loggers[logLevel]; ok {
-			event := f()
-			for k, v := range l.AdditionalData {
-				event = event.Str(k, v)
-			}
+// DisableEvents sets a flag for suppressing all log events, including
+// the banner. Traces are still counted in Stats and passed to interceptors
+// and the metrics hook, but no event is built.
+func (l *GormLogger) DisableEvents(b bool) {
+	l.disableEvents = b
+}
+
+// newEvent returns an event with additional data attached, or nil if
+// the event of the given level should not be logged.
+func (l *GormLogger) newEvent(logLevel logger.LogLevel, sampled bool) Event {
+	return l.newEventWith(context.Background(), logLevel, sampled)
+}
+
+// newEventWith is newEvent for a statement or message with the context ctx.
+func (l *GormLogger) newEventWith(ctx context.Context, logLevel logger.LogLevel, sampled bool) Event {
+	return l.newEventFrom(ctx, nil, logLevel, sampled)
+}
+
+// newEventFrom is newEventWith creating the event with the builder, if it is
+// not nil, instead of the builder of the level.
+func (l *GormLogger) newEventFrom(ctx context.Context, builder func() Event, logLevel logger.LogLevel, sampled bool) Event {
+	if l.levelFor(ctx) < logLevel {
+		return nil
+	}
+
+	return l.buildEventFrom(ctx, builder, logLevel, sampled)
+}
+
+// buildEvent returns an event with additional data attached regardless of
+// the current log level, or nil if the event is disabled. The event is
+// written with the logger of the context ctx, if any, and gets the fields
+// extracted from it.
+func (l *GormLogger) buildEvent(ctx context.Context, logLevel logger.LogLevel, sampled bool) Event {
+	return l.buildEventFrom(ctx, nil, logLevel, sampled)
+}
+
+// buildEventFrom is buildEvent creating the event with the builder, if it is
+// not nil, instead of the context logger or the builder of the level.
+func (l *GormLogger) buildEventFrom(ctx context.Context, builder func() Event, logLevel logger.LogLevel, sampled bool) Event {
+	if l.disableEvents {
+		return nil
+	}
+
+	var zl *zerolog.Logger
+	f, custom := l.loggers[logLevel]
+	if builder != nil {
+		f, custom = builder, true
+	} else {
+		zl = l.contextLogger(ctx)
+	}
 
-			event.Msgf(msg, data...)
+	var event Event
+	switch {
+	case zl != nil:
+		event = l.zerologEvent(*zl, logLevel, sampled)
+	case custom:
+		event = f()
+	default:
+		event = l.zerologEvent(l.zerologLogger(), logLevel, sampled)
+	}
+
+	if e, ok := event.(interface{ Enabled() bool }); event == nil || ok && !e.Enabled() {
+		return nil
+	}
+
+	// static fields are baked into the zerolog logger of built-in events only
+	if custom || zl != nil {
+		for k, v := range l.staticFields {
+			event = event.Any(k, v)
+		}
+	}
+
+	event = l.dbInfo.attach(event)
+
+	levelData := l.levelData[logLevel]
+	dynamic := l.dynamicData()
+	data := l.additionalData()
+	typed := l.typedFields(ctx)
+	for k, v := range l.AdditionalData {
+		if !hasKey(data, k) && !hasKey(l.fields, k) && !hasKey(typed, k) && !hasKey(levelData, k) && !hasKey(dynamic, k) {
+			event = event.Str(k, v)
+		}
+	}
+
+	for k, v := range data {
+		if !hasKey(l.fields, k) && !hasKey(typed, k) && !hasKey(levelData, k) && !hasKey(dynamic, k) {
+			event = event.Str(k, v)
+		}
+	}
+
+	for k, v := range l.fields {
+		if !hasKey(typed, k) && !hasKey(levelData, k) && !hasKey(dynamic, k) {
+			event = event.Str(k, v)
+		}
+	}
+
+	for k, v := range typed {
+		if !hasKey(levelData, k) && !hasKey(dynamic, k) {
+			event = event.Any(k, v)
+		}
+	}
+
+	for k, v := range levelData {
+		if !hasKey(dynamic, k) {
+			event = event.Str(k, v)
+		}
+	}
+
+	for k, v := range dynamic {
+		event = event.Str(k, v)
+	}
+
+	return event
+}
+
+func hasKey[V any](m map[string]V, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// dynamicData calls AdditionalDataFunc, recovering from its panics.
+func (l *GormLogger) dynamicData() (data map[string]string) {
+	if l.AdditionalDataFunc == nil {
+		return nil
+	}
+
+	defer func() {
+		if recover() != nil {
+			data = nil
+		}
+	}()
+
+	return l.AdditionalDataFunc()
+}
+
+// typedFields returns AdditionalFields merged with the fields of the field
+// providers and the fields extracted from the context ctx, including the span
+// IDs, recovering from their panics.
+func (l *GormLogger) typedFields(ctx context.Context) map[string]any {
+	if len(l.fieldProviders) == 0 && len(l.contextExtractors) == 0 && l.spanExtractor == nil {
+		return l.AdditionalFields
+	}
+
+	fields := maps.Clone(l.AdditionalFields)
+	if fields == nil {
+		fields = map[string]any{}
+	}
+
+	for _, provider := range l.fieldProviders {
+		func() {
+			defer func() { _ = recover() }()
+			maps.Copy(fields, provider())
+		}()
+	}
+
+	for _, extract := range l.contextExtractors {
+		func() {
+			defer func() { _ = recover() }()
+			maps.Copy(fields, extract(ctx))
+		}()
+	}
+
+	if l.spanExtractor != nil {
+		l.addSpanFields(ctx, fields)
+	}
+
+	return fields
+}
+
+// baseLogger holds the zerolog logger of the built-in event builders.
+// Updates are serialized by mu, reads are lock-free.
+type baseLogger struct {
+	mu     sync.Mutex
+	logger atomic.Pointer[zerolog.Logger]
+}
+
+func (b *baseLogger) clone() *baseLogger {
+	c := &baseLogger{}
+	c.logger.Store(b.logger.Load())
+	return c
+}
+
+// zerologLogger returns the zerolog logger used by the built-in event builders.
+func (l *GormLogger) zerologLogger() zerolog.Logger {
+	if zl := l.base.logger.Load(); zl != nil {
+		return *zl
+	}
+
+	return log.Logger
+}
+
+// zerologEvent creates an event with the zerolog logger,
+// applying the configured sampler if the event is sampled.
+func (l *GormLogger) zerologEvent(zl zerolog.Logger, logLevel logger.LogLevel, sampled bool) Event {
+	if s := l.samplerFor(logLevel); s != nil && sampled {
+		zl = zl.Sample(s)
+	}
+
+	var e *zerolog.Event
+	switch logLevel {
+	case Debug:
+		e = zl.Debug()
+	case logger.Info:
+		e = zl.Info()
+	case logger.Warn:
+		e = zl.Warn()
+	case logger.Error:
+		e = zl.Error()
+	default:
+		return nil
+	}
+
+	return &GormLoggerEvent{Event: e}
+}
+
+func (l *GormLogger) samplerFor(logLevel logger.LogLevel) zerolog.Sampler {
+	if s, ok := l.samplers[logLevel]; ok {
+		return s
+	}
+
+	return l.sampler
+}
+
+func (l *GormLogger) log(ctx context.Context, logLevel logger.LogLevel, msg string, data ...any) {
+	event := l.newEventWith(l.withTenant(ctx), logLevel, true)
+	if event == nil {
+		return
+	}
+
+	if l.zerologCaller {
+		if caller, _ := l.fileWithLineNum(); caller != "" {
+			event = event.Str(zerolog.CallerFieldName, caller)
 		}
 	}
+
+	event.Msgf(msg, data...)
 }
 
 // Info starts a new message with info level.
 func (l *GormLogger) Info(ctx context.Context, msg string, data ...any) {
-	l.log(logger.Info, msg, data...)
+	l.log(ctx, logger.Info, msg, data...)
 }
 
 // Warn starts a new message with warn level.
 func (l *GormLogger) Warn(ctx context.Context, msg string, data ...any) {
-	l.log(logger.Warn, msg, data...)
+	l.log(ctx, logger.Warn, msg, data...)
 }
 
 // Error starts a new message with error level.
 func (l *GormLogger) Error(ctx context.Context, msg string, data ...any) {
-	l.log(logger.Error, msg, data...)
+	l.log(ctx, logger.Error, msg, data...)
+}
+
+// TraceRecord holds everything known about a single traced statement.
+type TraceRecord struct {
+	Begin   time.Time
+	Elapsed time.Duration
+	SQL     string
+	Rows    int64
+	Err     error
+	Caller  string
+	// Cancelled reports whether the statement failed with context.Canceled
+	// or context.DeadlineExceeded.
+	Cancelled bool
+	// ErrorKind is the kind of the error classified with SetErrorKind.
+	ErrorKind string
+	// SQLState is the SQLSTATE code of a Postgres driver error and
+	// Constraint is the name of the violated constraint, if any.
+	SQLState   string
+	Constraint string
+	// MySQLErrno is the error number of a MySQL driver error, zero if none.
+	MySQLErrno uint64
+	// Retryable reports whether the statement failed with a deadlock, a lock
+	// wait timeout or a serialization failure.
+	Retryable bool
+	// CallerFunc is the caller function name, set if CallerFunc is enabled.
+	CallerFunc string
+	// CallStack are the frames leading to the statement, captured for
+	// failed and slow statements if CallStack is enabled.
+	CallStack []string
+	// DDL reports whether the statement is a schema definition or
+	// migration introspection statement.
+	DDL bool
+	// Table, Model and Operation describe the statement. They come from
+	// GORM statement metadata if the logger is attached with the Plugin,
+	// otherwise Table and Operation are parsed from the SQL.
+	Table     string
+	Model     string
+	Operation string
+	// DBTime is the time spent in the query callback and CallbacksTime is
+	// the rest of Elapsed. They are set only if the logger is attached with
+	// the Plugin.
+	DBTime        time.Duration
+	CallbacksTime time.Duration
+	// DryRun reports whether the statement was generated by a DryRun session
+	// and not executed. It is set only if the logger is attached with the Plugin.
+	DryRun bool
+
+	// TxID is an identifier of the transaction the statement was executed in.
+	TxID string
+	// TxSeq is the sequence number of the statement in the transaction,
+	// starting from 1. It is set together with TxID.
+	TxSeq int64
+	// InTx reports whether the statement was executed in an explicit
+	// transaction. It is set only if the logger is attached with the Plugin.
+	InTx bool
+	// PrimaryKeys are the primary keys of the model instances written by
+	// the statement, collected if AuditPrimaryKeys is enabled.
+	PrimaryKeys          []any
+	PrimaryKeysTruncated bool
+	// ParamCount is the number of bind parameters of the statement, -1 if
+	// unknown. It is known only if the logger is attached with the Plugin.
+	ParamCount int
+	// StatementID is the ID of the statement logged by LogBeforeExecution.
+	// It is set only if the logger is attached with the Plugin.
+	StatementID string
+	// DBTarget is the connection target set with WithDBTarget.
+	DBTarget string
+	// Tenant is the tenant of the statement resolved by WithTenants.
+	Tenant string
+	// InListSize is the number of elements of the largest IN list of
+	// the statement, set if it exceeds the WarnLargeInLists limit.
+	InListSize int
+	// UnboundedSelect reports whether the statement is a SELECT without
+	// LIMIT on a table watched with FlagUnboundedSelects.
+	UnboundedSelect bool
+	// DeadlineRemaining is the time left until the deadline of the context
+	// of the statement when it completed, negative if the deadline passed.
+	// It is set only if HasDeadline is set.
+	DeadlineRemaining time.Duration
+	HasDeadline       bool
+	// NoDeadline reports whether the statement was executed with a context
+	// without a deadline, set if FlagMissingDeadlines is enabled.
+	NoDeadline bool
+	// NoRowsAffected reports whether the statement is an UPDATE or DELETE
+	// which affected no rows, set if FlagNoRowsAffected is enabled.
+	NoRowsAffected bool
+	// RowsAffectedExcessive reports whether the statement is a write which
+	// affected more rows than the RowsAffectedWarnThreshold.
+	RowsAffectedExcessive bool
+	// TxOutcome is "commit" or "rollback" for statements buffered until the
+	// end of their transaction.
+	TxOutcome string
+	// FirstSeen reports whether the statement shape is seen for the first
+	// time. It is set only if LogFirstSeen is enabled.
+	FirstSeen bool
+	// SuppressedErrors is the number of occurrences of the error suppressed
+	// by SampleErrors since the last logged one.
+	SuppressedErrors int64
+	// DangerousStatement reports whether the statement matches a pattern
+	// set with EscalateStatements.
+	DangerousStatement bool
+	// LatencyRegression reports whether the statement is slower than its
+	// LatencyBaseline by the factor set with DetectLatencyRegressions.
+	LatencyRegression bool
+	LatencyBaseline   time.Duration
+
+	// quiet suppresses logging of the record unless it is an error, a slow
+	// query or escalated.
+	quiet bool
+	// dialector is the database info of the dialector of the database
+	// the statement runs on.
+	dialector DatabaseInfo
+	// unmatched suppresses logging of the record unless it is an error,
+	// a slow query or escalated, regardless of FirstSeen.
+	unmatched bool
+	// level is the level the record is escalated to by checks, zero if none.
+	level logger.LogLevel
+	// ctx is the context of the statement.
+	ctx context.Context
+	// threshold is the slow threshold of the statement.
+	threshold time.Duration
+	// errorLevel is the level of the error trace of the statement.
+	errorLevel logger.LogLevel
 }
 
 // Trace starts a new message with trace level.
 func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
-	if l.logLevel <= logger.Silent {
+	ctx = l.withTenant(ctx)
+	silent := l.levelFor(ctx) <= logger.Silent
+	if silent && l.audit == nil {
 		return
 	}
 
-	elapsed := time.Since(begin)
+	now := l.clock.Now()
+	elapsed := now.Sub(begin)
 	sql, rows := fc()
-	var rowsAffected any = rows
-	if rows == -1 {
-		rowsAffected = "-"
+	rec := &TraceRecord{
+		Begin:   begin,
+		Elapsed: elapsed,
+		SQL:     sql,
+		Rows:    rows,
+		Err:     err,
+		DDL:     isDDL(sql),
+
+		Cancelled: isCancellation(err),
+
+		ParamCount: -1,
+		DBTarget:   dbTargetFromContext(ctx),
+		ctx:        ctx,
+		threshold:  l.thresholdFor(ctx),
+	}
+
+	if l.tenants != nil {
+		rec.Tenant = l.tenants.tenant(ctx)
+	}
+
+	rec.dialector = dialectorInfoFromContext(ctx)
+
+	if ctx != nil {
+		if deadline, ok := ctx.Deadline(); ok {
+			rec.DeadlineRemaining, rec.HasDeadline = deadline.Sub(now), true
+		}
+	}
+
+	if info := statementInfoFromContext(ctx); info != nil {
+		rec.Table, rec.Model, rec.Operation = info.table, info.model, info.operation
+		rec.DryRun = info.dryRun
+		rec.InTx = info.inTx
+		rec.PrimaryKeys, rec.PrimaryKeysTruncated = info.pks, info.pksTruncated
+		rec.ParamCount = info.paramCount
+		rec.StatementID = info.id
+		if info.sqlComment != "" {
+			rec.SQL = strings.Replace(rec.SQL, " "+info.sqlComment, "", 1)
+		}
+		if info.dbTime > 0 {
+			rec.DBTime, rec.CallbacksTime = info.dbTime, max(elapsed-info.dbTime, 0)
+		}
+	}
+
+	if rec.Operation == "" {
+		rec.Operation = sqlOperation(sql)
 	}
 
+	if rec.Table == "" {
+		rec.Table = sqlTable(sql)
+	}
+
+	if l.audit != nil {
+		l.writeAudit(ctx, rec)
+	}
+
+	if silent {
+		return
+	}
+
+	if l.callerResolver != nil && !l.disableCaller {
+		rec.Caller = l.callerResolver(ctx)
+	} else {
+		rec.Caller, rec.CallerFunc = l.fileWithLineNum()
+	}
+
+	if l.stackDepth > 0 && !l.disableCaller && (l.isLoggedError(rec) || l.isSlow(rec)) {
+		rec.CallStack = l.callStack(l.stackDepth)
+	}
+
+	tx := txGroupFromContext(ctx)
+	if tx != nil {
+		rec.TxID = tx.id
+		rec.TxSeq = tx.seq.Add(1)
+	}
+
+	if rec.Err != nil {
+		var kindLevel logger.LogLevel
+		rec.ErrorKind, kindLevel = l.classifyError(rec.Err)
+		rec.SQLState, rec.Constraint = sqlState(rec.Err)
+		rec.MySQLErrno = mysqlErrno(rec.Err)
+		rec.Retryable = isRetryable(rec)
+		rec.errorLevel = l.errorLevel(rec, kindLevel)
+	}
+
+	l.check(ctx, rec)
+	l.stats.traces.Add(1)
+	rec.quiet = l.overloaded(now)
+	if !l.intercept(ctx, rec) {
+		return
+	}
+
+	if l.strict != nil {
+		l.failOnStrict(rec)
+	}
+
+	l.observe(rec)
+	if l.slowest != nil {
+		l.slowest.add(rec.SQL, rec.Elapsed)
+	}
+
+	if l.debug != nil {
+		l.debug.add(rec)
+	}
+
+	if l.disableEvents {
+		return
+	}
+
+	if !l.matches(rec) {
+		if l.matchErrors && !rec.DangerousStatement {
+			return
+		}
+
+		rec.unmatched = true
+	}
+
+	if l.firstSeen != nil {
+		rec.FirstSeen = l.firstSeen.add(fingerprint(rec.SQL))
+	}
+
+	if l.errorSampler != nil && l.isLoggedError(rec) && !rec.DangerousStatement {
+		var keep bool
+		keep, rec.SuppressedErrors = l.errorSampler.sample(errorGroupKey(rec.Err, rec.SQL))
+		if !keep {
+			return
+		}
+	}
+
+	if tx != nil {
+		if l.txBufferSize > 0 {
+			for _, r := range tx.add(rec, l.txBufferSize) {
+				l.dispatch(r)
+			}
+
+			return
+		}
+	}
+
+	l.dispatch(rec)
+}
+
+// dispatch passes the record to the async queue if it is running,
+// otherwise emits it synchronously.
+func (l *GormLogger) dispatch(rec *TraceRecord) {
+	if l.async != nil {
+		queued, dropped := l.async.enqueue(l, rec)
+		if dropped > 0 {
+			l.stats.droppedEvents.Add(dropped)
+			l.reportDropped(false)
+		}
+
+		if queued {
+			return
+		}
+	}
+
+	l.emitTrace(rec)
+}
+
+// traceKind is the branch a trace is logged on.
+type traceKind int
+
+const (
+	infoTrace traceKind = iota
+	slowTrace
+	errorTrace
+)
+
+// traceBuilder returns the event builder of the trace of the kind routed to
+// a dedicated builder, or nil. Dangerous statements are never routed.
+func (l *GormLogger) traceBuilder(rec *TraceRecord, kind traceKind) func() Event {
+	if rec.DangerousStatement {
+		return nil
+	}
+
+	switch kind {
+	case errorTrace:
+		if rec.Retryable {
+			return l.retryableBuilder
+		}
+	case slowTrace:
+		return l.slowBuilder
+	}
+
+	return nil
+}
+
+func (l *GormLogger) traceLog(logLevel logger.LogLevel, rec *TraceRecord, kind traceKind) {
+	event := l.newEventFrom(rec.ctx, l.traceBuilder(rec, kind), logLevel, !rec.FirstSeen && !rec.DangerousStatement)
+	if event == nil {
+		return
+	}
+
+	event = l.dialectorInfo(rec.dialector).attach(event)
+
+	defaults := defaultTemplates[l.sqlPlacement]
+	tmpl, message := l.templates.info.pick(defaults.info), ""
+	switch kind {
+	case slowTrace:
+		tmpl, message = l.templates.slow.pick(defaults.slow), l.slowQueryNotice(rec.threshold)
+	case errorTrace:
+		tmpl, message = l.templates.err.pick(defaults.err), rec.Err.Error()
+	}
+
+	problem := kind != infoTrace
+
+	if rec.DangerousStatement {
+		event = event.Any("dangerous_statement", true)
+	}
+
+	if rec.FirstSeen {
+		event = event.Any("first_seen", true)
+	}
+
+	if rec.SuppressedErrors > 0 {
+		event = event.Any("suppressed_since_last", rec.SuppressedErrors)
+	}
+
+	if rec.StatementID != "" {
+		event = event.Str(l.fieldNames.StatementID, rec.StatementID)
+	}
+
+	caller := rec.Caller
+	switch {
+	case caller == "":
+	case l.zerologCaller:
+		event = event.Str(zerolog.CallerFieldName, caller)
+		caller = ""
+	case l.callerField:
+		event = event.Str(l.fieldNames.Caller, caller)
+		caller = ""
+	}
+
+	if rec.CallerFunc != "" {
+		event = event.Str("caller_func", rec.CallerFunc)
+	}
+
+	if rec.DDL {
+		event = event.Any("ddl", true)
+	}
+
+	if rec.DryRun {
+		event = event.Any("dry_run", true)
+	}
+
+	if rec.Operation != "" {
+		event = event.Str(l.fieldNames.Operation, rec.Operation)
+	}
+
+	if rec.Table != "" {
+		event = event.Str(l.fieldNames.Table, rec.Table)
+	}
+
+	if rec.Model != "" {
+		event = event.Str(l.fieldNames.Model, rec.Model)
+	}
+
+	if rec.DBTarget != "" {
+		event = event.Str("db_target", rec.DBTarget)
+	}
+
+	if rec.InTx {
+		event = event.Any("in_tx", true)
+	}
+
+	if len(rec.PrimaryKeys) > 0 {
+		event = event.Any("pk", rec.PrimaryKeys)
+	}
+
+	if rec.PrimaryKeysTruncated {
+		event = event.Any("pk_truncated", true)
+	}
+
+	if rec.ParamCount >= 0 {
+		event = event.Any("param_count", rec.ParamCount)
+	}
+
+	if rec.InListSize > 0 {
+		event = event.Any("large_in_list", true).Any("in_list_size", rec.InListSize)
+	}
+
+	if rec.UnboundedSelect {
+		event = event.Any("unbounded_select", true)
+	}
+
+	if rec.Cancelled {
+		event = event.Any("cancelled", true)
+	}
+
+	if rec.ErrorKind != "" {
+		event = event.Str("error_kind", rec.ErrorKind)
+	}
+
+	if rec.Retryable {
+		event = event.Any("retryable", true)
+	}
+
+	if rec.SQLState != "" {
+		event = event.Str("sqlstate", rec.SQLState)
+	}
+
+	if rec.Constraint != "" {
+		event = event.Str("constraint", rec.Constraint)
+	}
+
+	if rec.MySQLErrno != 0 {
+		event = event.Any("mysql_errno", rec.MySQLErrno)
+		if class, ok := mysqlClasses[rec.MySQLErrno]; ok {
+			event = event.Str("mysql_error_class", class)
+		}
+	}
+
+	if rec.HasDeadline {
+		event = event.Any("deadline_remaining_ms", float64(rec.DeadlineRemaining.Nanoseconds())/1e6)
+	}
+
+	if rec.NoDeadline {
+		event = event.Any("no_deadline", true)
+	}
+
+	if rec.NoRowsAffected {
+		event = event.Any("no_rows_affected", true)
+	}
+
+	if rec.RowsAffectedExcessive {
+		event = event.Any("rows_affected_excessive", true)
+	}
+
+	if rec.LatencyRegression {
+		event = event.Any("latency_regression", true).
+			Any("latency_baseline", rec.LatencyBaseline).
+			Any("latency_observed", rec.Elapsed)
+	}
+
+	if rec.TxID != "" {
+		event = event.Str("tx_id", rec.TxID).Any("tx_seq", rec.TxSeq)
+	}
+
+	if rec.TxOutcome != "" {
+		event = event.Str("tx_outcome", rec.TxOutcome)
+	}
+
+	if rec.Rows != -1 {
+		event = event.Any(l.rowsFields.rows, rec.Rows)
+		if name := l.rowsFields.name(rec.SQL); name != l.rowsFields.rows {
+			event = event.Any(name, rec.Rows)
+		}
+	} else {
+		event = event.Any("rows_unknown", true)
+	}
+
+	event = event.Dur(l.fieldNames.Elapsed, rec.Elapsed)
+	if l.sqlPlacement == Structured {
+		event = event.Any(l.fieldNames.ElapsedMs, float64(rec.Elapsed.Nanoseconds())/1e6)
+	}
+
+	if kind == slowTrace {
+		event = event.Any(l.fieldNames.Slow, true).Any(l.fieldNames.SlowThreshold, float64(rec.threshold.Nanoseconds())/1e6)
+	}
+
+	if (problem || l.phaseTimesOnAll) && rec.DBTime > 0 {
+		event = event.Any("db_time", rec.DBTime).Any("callbacks_time", rec.CallbacksTime)
+	}
+
+	if problem && len(rec.CallStack) > 0 {
+		event = event.Any("call_stack", rec.CallStack)
+	}
+
+	if l.latencyBuckets != nil {
+		event = event.Str("latency_bucket", l.latencyBuckets.label(rec.Elapsed))
+	}
+
+	includeSQL := l.includesSQL(logLevel)
+	if l.queryFingerprints || l.normalizedSQL && includeSQL {
+		normalized := normalizeSQL(rec.SQL)
+		if l.queryFingerprints {
+			event = event.Str(l.fieldNames.QueryFingerprint, fmt.Sprintf("%016x", normalizedFingerprint(normalized)))
+		}
+
+		if l.normalizedSQL && includeSQL {
+			normalized, _ = truncateSQL(normalized, l.sqlLimit)
+			event = event.Str(l.fieldNames.SQLNormalized, normalized)
+		}
+	}
+
+	sql, truncated := truncateSQL(rec.SQL, l.sqlLimit)
+	if includeSQL && truncated {
+		event = event.Any("sql_truncated", true)
+	}
+
+	if l.sqlPlacement != OwnLine && includeSQL {
+		event = event.Str(l.fieldNames.SQL, sql)
+	}
+
+	event.Msgf("%s", tmpl.render(templateValues{
+		caller:      caller,
+		message:     message,
+		elapsed:     rec.Elapsed,
+		rows:        rec.Rows,
+		unknownRows: l.literals.UnknownRows,
+		sql:         sql,
+		omitSQL:     !includeSQL,
+	}))
+}
+
+// emitDangerous logs the record escalated by EscalateStatements as a single
+// error event on the branch it would be logged on otherwise.
+func (l *GormLogger) emitDangerous(rec *TraceRecord) {
 	switch {
-	case err != nil && (!errors.Is(err, logger.ErrRecordNotFound) || !l.ignoreRecordNotFoundErr):
-		l.log(logger.Error, traceErrMsg, fileWithLineNum(), err, float64(elapsed.Nanoseconds())/1e6, rowsAffected, sql)
-	case elapsed > l.slowThreshold && l.slowThreshold != 0:
-		slowLog := fmt.Sprintf("SLOW SQL >= %v", l.slowThreshold)
-		l.log(logger.Warn, traceWarnMsg, fileWithLineNum(), slowLog, float64(elapsed.Nanoseconds())/1e6, rowsAffected, sql)
+	case l.isLoggedError(rec):
+		l.traceLog(logger.Error, rec, errorTrace)
+	case l.isSlow(rec):
+		l.traceLog(logger.Error, rec, slowTrace)
+	default:
+		l.traceLog(logger.Error, rec, infoTrace)
 	}
+}
 
-	l.log(logger.Info, traceInfoMsg, fileWithLineNum(), float64(elapsed.Nanoseconds())/1e6, rowsAffected, sql)
+// isSlow reports whether the elapsed time of the record exceeds its slow
+// threshold.
+func (l *GormLogger) isSlow(rec *TraceRecord) bool {
+	return rec.Elapsed > rec.threshold && rec.threshold != 0
 }
 
-var gormSourceDir string
+// isLoggedError reports whether the error of the record is logged at error
+// level.
+func (l *GormLogger) isLoggedError(rec *TraceRecord) bool {
+	err := rec.Err
+	if err == nil || errors.Is(err, logger.ErrRecordNotFound) && l.ignoreRecordNotFoundErr {
+		return false
+	}
 
-// fileWithLineNum return the file name and line number of the current file
-func fileWithLineNum() string {
-	// the second caller usually from gorm internal, so set i start from 2
-	for i := 2; i < 15; i++ {
-		_, file, line, ok := runtime.Caller(i)
-		if ok && (!strings.HasPrefix(file, gormSourceDir) || strings.HasSuffix(file, "_test.go")) {
-			return file + ":" + strconv.FormatInt(int64(line), 10)
+	for _, ignored := range l.ignoredErrors {
+		if errors.Is(err, ignored) {
+			return false
 		}
 	}
 
-	return ""
+	return l.errorFilter == nil || l.filterError(err, rec.SQL)
 }
 
-func init() {
-	_, file, _, _ := runtime.Caller(0)
-	// compatible solution to get gorm source directory with various operating systems
-	gormSourceDir = regexp.MustCompile(`gorm.utils.utils\.go`).ReplaceAllString(file, "")
+func (l *GormLogger) emitTrace(rec *TraceRecord) {
+	if rec.DangerousStatement {
+		l.emitDangerous(rec)
+		return
+	}
+
+	var problem bool
+	switch {
+	case l.isLoggedError(rec):
+		if rec.errorLevel > logger.Silent {
+			l.traceLog(rec.errorLevel, rec, errorTrace)
+			problem = true
+		}
+	case l.isSlow(rec):
+		l.traceLog(logger.Warn, rec, slowTrace)
+		problem = true
+	}
+
+	if problem && l.singleEvent {
+		return
+	}
+
+	infoLevel := logger.Info
+	switch {
+	case rec.DryRun:
+		infoLevel = l.dryRunLevel
+	case rec.DDL:
+		infoLevel = l.ddlLevel
+	}
+
+	// checks flagging a statement at its default level don't escalate it
+	escalated := rec.level != 0 && rec.level < infoLevel
+	if (rec.quiet && !rec.FirstSeen || rec.unmatched) && !escalated {
+		return
+	}
+
+	if escalated {
+		infoLevel = rec.level
+	}
+
+	l.traceLog(infoLevel, rec, infoTrace)
 }
@@ -1,20 +1,33 @@
 package gormzerolog
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/glebarez/sqlite"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 type testingEvent struct {
-	added map[string]string
-	msg   string
+	added  map[string]string
+	fields map[string]any
+	msg    string
 }
 
 func (e *testingEvent) Str(key, value string) Event {
@@ -26,10 +39,47 @@ func (e *testingEvent) Str(key, value string) Event {
 	return e
 }
 
+func (e *testingEvent) Any(key string, value any) Event {
+	if e.fields == nil {
+		e.fields = map[string]any{}
+	}
+
+	e.fields[key] = value
+	return e
+}
+
+func (e *testingEvent) Dur(key string, d time.Duration) Event {
+	return e.Any(key, d)
+}
+
 func (e *testingEvent) Msgf(format string, v ...any) {
 	e.msg = fmt.Sprintf(format, v...)
 }
 
+type testingSink struct {
+	mu     sync.Mutex
+	events []*testingEvent
+}
+
+func (s *testingSink) factory() Event {
+	e := &testingEvent{}
+	s.mu.Lock()
+	s.events = append(s.events, e)
+	s.mu.Unlock()
+	return e
+}
+
+func (s *testingSink) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msgs := make([]string, 0, len(s.events))
+	for _, e := range s.events {
+		msgs = append(msgs, e.msg)
+	}
+
+	return msgs
+}
+
 func TestGormLogger(t *testing.T) {
 	getEventFactory := func(e Event) func() Event {
 		return func() Event { return e }
@@ -50,10 +100,12 @@ func TestGormLogger(t *testing.T) {
 			errorEvent  = &testingEvent{}
 			targetEvent *testingEvent
 			emptyEvents []*testingEvent
+			clock       = &testingClock{now: time.Unix(0, 0)}
 			l           = NewGormLogger().
 					WithInfo(getEventFactory(infoEvent)).
 					WithWarn(getEventFactory(warnEvent)).
 					WithError(getEventFactory(errorEvent)).
+					WithClock(clock).
 					LogMode(logLevel).(*GormLogger)
 
 			str1 = uuid.NewString()
@@ -76,11 +128,11 @@ func TestGormLogger(t *testing.T) {
 		assert.False(l.ignoreRecordNotFoundErr, "ignoreRecordNotFoundErr should be false")
 		l.IgnoreRecordNotFoundError(true)
 		assert.True(l.ignoreRecordNotFoundErr, "ignoreRecordNotFoundErr should be true")
-		assert.Equal(l.slowThreshold, time.Millisecond*200)
+		assert.Equal(l.threshold(), time.Millisecond*200)
 		l.SlowThreshold(time.Millisecond * 600)
-		assert.Equal(l.slowThreshold, time.Millisecond*600)
+		assert.Equal(l.threshold(), time.Millisecond*600)
 		l.AdditionalData = map[string]string{str1: str1, str2: str2, str3: str3}
-		assert.Equalf(logLevel, l.logLevel, "logLevel should be %d", logLevel)
+		assert.Equalf(logLevel, l.level(), "logLevel should be %d", logLevel)
 		switch logLevel {
 		case logger.Info:
 			l.Info(context.Background(), msg, str5)
@@ -104,7 +156,7 @@ func TestGormLogger(t *testing.T) {
 		}
 
 		clearEvents()
-		l.LogMode(logger.Silent)
+		l.SetLogLevel(logger.Silent)
 		switch logLevel {
 		case logger.Info:
 			l.Info(context.Background(), msg, str5)
@@ -125,7 +177,7 @@ func TestGormLogger(t *testing.T) {
 			assert.Empty(e.msg)
 		}
 
-		l.LogMode(logLevel)
+		l.SetLogLevel(logLevel)
 		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "test", 0 }, errors.New("test"))
 		assert.NotEmpty(errorEvent.added)
 		assert.NotEmpty(errorEvent.msg)
@@ -140,7 +192,7 @@ func TestGormLogger(t *testing.T) {
 		}
 
 		clearEvents()
-		l.Trace(context.Background(), time.Now().Add(-l.slowThreshold*2), func() (string, int64) { return "test", -1 }, nil)
+		l.Trace(context.Background(), clock.Now().Add(-l.threshold()*2), func() (string, int64) { return "test", -1 }, nil)
 		assert.Empty(errorEvent.added)
 		assert.Empty(errorEvent.msg)
 		if logLevel >= logger.Warn {
@@ -163,3 +215,591 @@ func TestGormLogger(t *testing.T) {
 	t.Run("warn test", func(t *testing.T) { levelTest(logger.Warn) })
 	t.Run("error test", func(t *testing.T) { levelTest(logger.Error) })
 }
+
+func TestGormLoggerDDL(t *testing.T) {
+	assert := assert.New(t)
+	debugSink := &testingSink{}
+	infoSink := &testingSink{}
+	l := NewGormLogger().WithDebug(debugSink.factory).WithInfo(infoSink.factory).LogMode(Debug).(*GormLogger)
+	l.DDLLevel(Debug)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: l})
+	require.NoError(t, err)
+
+	type Item struct {
+		ID   uint
+		Name string `gorm:"index"`
+	}
+
+	require.NoError(t, db.AutoMigrate(&Item{}))
+	require.NoError(t, db.Create(&Item{Name: "a"}).Error)
+	debugMsgs := debugSink.messages()
+	assert.NotEmpty(debugMsgs)
+	assert.True(slices.ContainsFunc(debugMsgs, func(msg string) bool { return strings.Contains(msg, "CREATE TABLE") }))
+	assert.True(slices.ContainsFunc(debugMsgs, func(msg string) bool { return strings.Contains(msg, "CREATE INDEX") }))
+	assert.True(slices.ContainsFunc(debugMsgs, func(msg string) bool { return strings.Contains(msg, "sqlite_master") }))
+	for _, e := range debugSink.events {
+		assert.Equal(true, e.fields["ddl"])
+	}
+
+	infoMsgs := infoSink.messages()
+	require.Len(t, infoMsgs, 1)
+	assert.Contains(infoMsgs[0], "INSERT INTO")
+	assert.NotContains(infoSink.events[0].fields, "ddl")
+
+	debugSink.events = nil
+	infoSink.events = nil
+	l.SetLogLevel(logger.Info)
+	require.NoError(t, db.Migrator().DropTable(&Item{}))
+	assert.Empty(debugSink.messages())
+	assert.Empty(infoSink.messages())
+}
+
+func TestGormLoggerSampler(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	globalLogger := log.Logger
+	log.Logger = zerolog.New(buf)
+	defer func() { log.Logger = globalLogger }()
+
+	l := NewGormLogger().
+		WithSampler(&zerolog.BasicSampler{N: 4}).
+		WithLevelSampler(logger.Info, &zerolog.BasicSampler{N: 2}).
+		WithLevelSampler(logger.Error, nil)
+	l.SlowThreshold(time.Millisecond)
+	for i := 0; i < 100; i++ {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("test"))
+		l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	counts := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		counts[entry["level"].(string)]++
+	}
+
+	assert.Equal(100, counts["error"])
+	assert.Equal(25, counts["warn"])
+	assert.Equal(100, counts["info"])
+}
+
+func TestGormLoggerAdditionalDataFunc(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	calls := 0
+	l := NewGormLogger().WithInfo(sink.factory)
+	l.AdditionalData = map[string]string{"static": "static", "phase": "static"}
+	l.AdditionalDataFunc = func() map[string]string {
+		calls++
+		return map[string]string{"phase": strconv.Itoa(calls)}
+	}
+
+	l.Info(context.Background(), "first")
+	l.Info(context.Background(), "second")
+	require.Len(t, sink.events, 2)
+	assert.Equal(map[string]string{"static": "static", "phase": "1"}, sink.events[0].added)
+	assert.Equal(map[string]string{"static": "static", "phase": "2"}, sink.events[1].added)
+
+	l.SetLogLevel(logger.Silent)
+	l.Info(context.Background(), "silent")
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	l.SetLogLevel(logger.Warn)
+	l.Info(context.Background(), "filtered")
+	assert.Equal(2, calls)
+
+	l.SetLogLevel(logger.Info)
+	l.AdditionalDataFunc = func() map[string]string { panic("test") }
+	assert.NotPanics(func() { l.Info(context.Background(), "panic") })
+	require.Len(t, sink.events, 3)
+	assert.Equal("panic", sink.events[2].msg)
+	assert.Equal(map[string]string{"static": "static", "phase": "static"}, sink.events[2].added)
+}
+
+func TestGormLoggerElapsedField(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	zl := zerolog.New(buf)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	l := NewGormLogger().WithInfo(func() Event { return &GormLoggerEvent{Event: zl.Info()} }).WithClock(clock)
+	l.Trace(context.Background(), clock.Now().Add(-2500*time.Microsecond), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+	entry := map[string]any{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(2.5, entry["elapsed"])
+
+	buf.Reset()
+	unit := zerolog.DurationFieldUnit
+	zerolog.DurationFieldUnit = time.Microsecond
+	defer func() { zerolog.DurationFieldUnit = unit }()
+	l.Trace(context.Background(), clock.Now().Add(-2500*time.Microsecond), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(2500.0, entry["elapsed"])
+}
+
+func TestGormLoggerDeadlineRemaining(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithClock(clock)
+	trace := func(ctx context.Context) {
+		l.Trace(ctx, clock.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(25*time.Millisecond))
+	defer cancel()
+	trace(ctx)
+	past, cancel := context.WithDeadline(context.Background(), clock.Now().Add(-time.Millisecond))
+	defer cancel()
+	trace(past)
+	trace(context.Background())
+
+	require.Len(t, sink.events, 3)
+	assert.Equal(25.0, sink.events[0].fields["deadline_remaining_ms"])
+	assert.Equal(-1.0, sink.events[1].fields["deadline_remaining_ms"])
+	assert.NotContains(sink.events[2].fields, "deadline_remaining_ms")
+}
+
+func TestGormLoggerSingleEventPerQuery(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithWarn(sink.factory).WithError(sink.factory).WithClock(clock)
+	l.SingleEventPerQuery(true)
+	trace := func(elapsed time.Duration, err error) {
+		l.Trace(context.Background(), clock.Now().Add(-elapsed), func() (string, int64) { return "SELECT 1", 1 }, err)
+	}
+
+	trace(0, errors.New("locked"))
+	trace(time.Second, nil)
+	trace(0, nil)
+	require.Len(t, sink.events, 3)
+	assert.Contains(sink.events[0].msg, "locked")
+	assert.Equal(true, sink.events[1].fields["slow"])
+	assert.NotContains(sink.events[2].fields, "slow")
+
+	l.SingleEventPerQuery(false)
+	trace(0, errors.New("locked"))
+	assert.Len(sink.events, 5)
+}
+
+func TestNewWithLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewWithLogger(zerolog.New(buf).With().Str("app", "test").Logger())
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	l.Warn(context.Background(), "warned")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for i, level := range []string{"info", "warn"} {
+		entry := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &entry))
+		assert.Equal(t, level, entry["level"])
+		assert.Equal(t, "test", entry["app"])
+	}
+}
+
+func TestGormLoggerAdditionalFields(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	zl := zerolog.New(buf)
+	ts := time.Date(2023, 11, 5, 10, 30, 0, 0, time.UTC)
+	l := NewGormLogger().WithInfo(func() Event { return &GormLoggerEvent{Event: zl.Info()} })
+	l.AdditionalData = map[string]string{"shard": "legacy", "service": "svc", "phase": "legacy"}
+	l.AdditionalFields = map[string]any{
+		"shard":   7,
+		"primary": true,
+		"ratio":   0.5,
+		"started": ts,
+		"phase":   "typed",
+	}
+	l.AdditionalDataFunc = func() map[string]string { return map[string]string{"phase": "dynamic"} }
+	l.Info(context.Background(), "test")
+
+	entry := map[string]any{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(float64(7), entry["shard"])
+	assert.Equal(true, entry["primary"])
+	assert.Equal(0.5, entry["ratio"])
+	assert.Equal(ts.Format(zerolog.TimeFieldFormat), entry["started"])
+	assert.Equal("svc", entry["service"])
+	assert.Equal("dynamic", entry["phase"])
+	assert.Equal(1, strings.Count(buf.String(), `"shard"`))
+	assert.Equal(1, strings.Count(buf.String(), `"phase"`))
+}
+
+func TestGormLoggerAddField(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	l := NewWithLogger(zerolog.New(buf))
+	shared := map[string]any{"shard": 7}
+	l.AdditionalFields = shared
+	l.AddField("primary", true)
+	l.AddField("timeout", 1500*time.Millisecond)
+	l.AddField("last_error", errors.New("failover"))
+	l.Info(context.Background(), "test")
+
+	entry := map[string]any{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(7.0, entry["shard"])
+	assert.Equal(true, entry["primary"])
+	assert.Equal(1500.0, entry["timeout"])
+	assert.Equal("failover", entry["last_error"])
+	assert.Len(shared, 1)
+}
+
+func TestGormLoggerWithFieldProvider(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	color := "blue"
+	base := NewGormLogger().WithInfo(sink.factory)
+	base.AdditionalFields = map[string]any{"color": "none", "shard": 7}
+	l := base.
+		WithFieldProvider(func() map[string]any { return map[string]any{"color": color, "flag": false} }).
+		WithFieldProvider(func() map[string]any { return map[string]any{"flag": true} }).
+		WithFieldProvider(func() map[string]any { panic("test") })
+
+	l.Info(context.Background(), "test")
+	color = "green"
+	l.Info(context.Background(), "test")
+	base.Info(context.Background(), "base")
+	require.Len(t, sink.events, 3)
+	assert.Equal(map[string]any{"color": "blue", "flag": true, "shard": 7}, sink.events[0].fields)
+	assert.Equal("green", sink.events[1].fields["color"])
+	assert.Equal(map[string]any{"color": "none", "shard": 7}, sink.events[2].fields)
+}
+
+func TestGormLoggerAdditionalDataForLevel(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithError(errorSink.factory)
+	l.AdditionalData = map[string]string{"service": "svc", "route": "global"}
+	l.SetAdditionalDataFor(logger.Error, map[string]string{"runbook": "https://runbook", "route": "oncall"})
+	l.SetAdditionalDataFor(logger.Warn, map[string]string{"slow": "yes"})
+	l.SlowThreshold(time.Millisecond)
+
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("test"))
+	l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	require.Len(t, errorSink.events, 1)
+	assert.Equal(map[string]string{"service": "svc", "runbook": "https://runbook", "route": "oncall", "operation": "select"}, errorSink.events[0].added)
+	require.Len(t, warnSink.events, 1)
+	assert.Equal(map[string]string{"service": "svc", "route": "global", "slow": "yes", "operation": "select"}, warnSink.events[0].added)
+	require.Len(t, infoSink.events, 2)
+	for _, e := range infoSink.events {
+		assert.Equal(map[string]string{"service": "svc", "route": "global", "operation": "select"}, e.added)
+	}
+}
+
+func TestGormLoggerStaticFields(t *testing.T) {
+	t.Run("built-in", func(t *testing.T) {
+		assert := assert.New(t)
+		buf := &bytes.Buffer{}
+		globalLogger := log.Logger
+		log.Logger = zerolog.New(buf)
+		defer func() { log.Logger = globalLogger }()
+
+		l := NewGormLogger().WithStaticFields(map[string]string{"service": "svc", "region": "eu"})
+		l.SetLogLevel(Debug)
+		l.DDLLevel(Debug)
+		l.SlowThreshold(time.Millisecond)
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "CREATE TABLE t (id int)", 0 }, nil)
+		l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("test"))
+
+		levels := map[string]bool{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			entry := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			assert.Equal("svc", entry["service"])
+			assert.Equal("eu", entry["region"])
+			levels[entry["level"].(string)] = true
+		}
+
+		assert.Equal(map[string]bool{"debug": true, "info": true, "warn": true, "error": true}, levels)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		sink := &testingSink{}
+		l := NewGormLogger().WithStaticFields(map[string]string{"service": "svc"}).WithInfo(sink.factory)
+		l.Info(context.Background(), "test")
+		require.Len(t, sink.events, 1)
+		assert.Equal(t, map[string]any{"service": "svc"}, sink.events[0].fields)
+	})
+}
+
+func BenchmarkGormLoggerStaticFields(b *testing.B) {
+	globalLogger := log.Logger
+	log.Logger = zerolog.New(io.Discard)
+	defer func() { log.Logger = globalLogger }()
+
+	fields := map[string]string{"service": "svc", "region": "eu-west-1", "cluster": "main"}
+	fc := func() (string, int64) { return "SELECT * FROM users WHERE id = 1", 1 }
+	b.Run("additional data", func(b *testing.B) {
+		l := NewGormLogger()
+		l.AdditionalData = fields
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Trace(context.Background(), time.Now(), fc, nil)
+		}
+	})
+
+	b.Run("static fields", func(b *testing.B) {
+		l := NewGormLogger().WithStaticFields(fields)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Trace(context.Background(), time.Now(), fc, nil)
+		}
+	})
+}
+
+func TestGormLoggerWithFields(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	base := NewGormLogger().WithInfo(sink.factory)
+	base.AdditionalData = map[string]string{"legacy": "legacy", "module": "legacy"}
+	derived := base.WithField("module", "orders").WithFields(map[string]string{"region": "eu"})
+	base.AdditionalData["legacy"] = "changed"
+
+	base.Info(context.Background(), "base")
+	derived.Info(context.Background(), "derived")
+	require.Len(t, sink.events, 2)
+	assert.Equal(map[string]string{"legacy": "changed", "module": "legacy"}, sink.events[0].added)
+	assert.Equal(map[string]string{"legacy": "legacy", "module": "orders", "region": "eu"}, sink.events[1].added)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l := derived.WithField("worker", strconv.Itoa(i))
+			for j := 0; j < 100; j++ {
+				l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	workers := map[string]bool{}
+	for _, e := range sink.events[2:] {
+		assert.Equal("orders", e.added["module"])
+		workers[e.added["worker"]] = true
+	}
+
+	assert.Len(sink.events, 802)
+	assert.Len(workers, 8)
+}
+
+func TestGormLoggerWithBuilderCopies(t *testing.T) {
+	assert := assert.New(t)
+	baseSink := &testingSink{}
+	ordersSink := &testingSink{}
+	base := NewGormLogger().WithInfo(baseSink.factory).WithWarn(baseSink.factory)
+	orders := base.WithInfo(ordersSink.factory)
+	assert.NotSame(base, orders)
+
+	base.Info(context.Background(), "base")
+	base.Warn(context.Background(), "base")
+	orders.Info(context.Background(), "orders")
+	orders.Warn(context.Background(), "orders")
+	assert.Len(baseSink.events, 3)
+	assert.Len(ordersSink.events, 1)
+}
+
+func TestGormLoggerWithSlow(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	warnSink := &testingSink{}
+	slowSink := &testingSink{}
+	nop := func() Event { return &testingEvent{} }
+	base := NewGormLogger().WithInfo(nop).WithWarn(warnSink.factory).WithError(nop).WithClock(clock)
+	l := base.WithSlow(slowSink.factory)
+	trace := func(l *GormLogger) {
+		l.Trace(context.Background(), clock.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	trace(l)
+	l.Warn(context.Background(), "warning")
+	require.Len(t, slowSink.events, 1)
+	assert.Equal(true, slowSink.events[0].fields["slow"])
+	require.Len(t, warnSink.events, 1)
+	assert.Contains(warnSink.events[0].msg, "warning")
+
+	trace(base)
+	assert.Len(warnSink.events, 2)
+	assert.Len(slowSink.events, 1)
+
+	errorSink := &testingSink{}
+	l = l.WithError(errorSink.factory)
+	require.NoError(t, l.EscalateStatements("DELETE FROM"))
+	l.Trace(context.Background(), clock.Now().Add(-time.Second), func() (string, int64) { return "DELETE FROM users", 1 }, nil)
+	assert.Len(slowSink.events, 1, "dangerous statements should be logged with the error builder")
+	require.Len(t, errorSink.events, 1)
+	assert.Equal(true, errorSink.events[0].fields["dangerous_statement"])
+}
+
+func TestGormLoggerWithCopies(t *testing.T) {
+	assert := assert.New(t)
+	base := NewGormLogger()
+	resolver := func(context.Context) string { return "app.go:1" }
+	marshaler := func(string, int, string) string { return "" }
+	clock := &testingClock{now: time.Unix(0, 0)}
+	for name, c := range map[string]*GormLogger{
+		"sampler":       base.WithSampler(&zerolog.BasicSampler{N: 2}),
+		"level sampler": base.WithLevelSampler(logger.Info, &zerolog.BasicSampler{N: 2}),
+		"static fields": base.WithStaticFields(map[string]string{"service": "svc"}),
+		"process info":  base.WithProcessInfo(ProcessInfo{Service: "svc"}),
+		"database info": base.WithDatabaseInfo(DatabaseInfo{Name: "orders"}),
+		"resolver":      base.WithCallerResolver(resolver),
+		"marshaler":     base.WithCallerMarshaler(marshaler),
+		"clock":         base.WithClock(clock),
+		"testing tb":    base.WithTestingTB(t),
+	} {
+		assert.NotSame(base, c, name)
+	}
+
+	assert.Nil(base.sampler)
+	assert.Empty(base.samplers)
+	assert.Empty(base.staticFields)
+	assert.Equal(DatabaseInfo{}, base.dbInfo)
+	assert.Nil(base.callerResolver)
+	assert.Nil(base.callerMarshaler)
+	assert.Equal(realClock{}, base.clock)
+	assert.Nil(base.strict)
+}
+
+func TestGormLoggerLogModeCopies(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory)
+	l.SetLogLevel(logger.Warn)
+	session := l.LogMode(logger.Info)
+
+	l.Info(context.Background(), "base")
+	assert.Empty(sink.events)
+	session.Info(context.Background(), "session")
+	assert.Len(sink.events, 1)
+
+	l.SetLogLevel(logger.Info)
+	l.Info(context.Background(), "base")
+	assert.Len(sink.events, 2)
+}
+
+func TestGormLoggerClone(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory)
+	l.AdditionalData = map[string]string{"module": "base"}
+	l.AdditionalFields = map[string]any{"shard": 1}
+	c := l.Clone()
+	c.AdditionalData["module"] = "orders"
+	c.AdditionalFields["shard"] = 2
+	c.SetLogLevel(logger.Warn)
+	c.SlowThreshold(time.Second)
+
+	assert.Equal("base", l.AdditionalData["module"])
+	assert.Equal(1, l.AdditionalFields["shard"])
+	assert.Equal(logger.Info, l.level())
+	assert.Equal(200*time.Millisecond, l.threshold())
+
+	l.Info(context.Background(), "base")
+	c.SetLogLevel(logger.Info)
+	c.Info(context.Background(), "clone")
+	require.Len(t, sink.events, 2)
+	assert.Equal("base", sink.events[0].added["module"])
+	assert.Equal("orders", sink.events[1].added["module"])
+	assert.Equal(2, sink.events[1].fields["shard"])
+}
+
+func TestGormLoggerUpdateLoggerContext(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	globalLogger := log.Logger
+	log.Logger = zerolog.New(zerolog.SyncWriter(buf))
+	defer func() { log.Logger = globalLogger }()
+
+	l := NewGormLogger().WithStaticFields(map[string]string{"service": "svc"})
+	l.Info(context.Background(), "before")
+	before := buf.String()
+
+	l.UpdateLoggerContext(func(zc zerolog.Context) zerolog.Context {
+		return zc.Int("shard", 7)
+	})
+	l.Info(context.Background(), "after")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(before, lines[0]+"\n")
+	entries := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &entries[i]))
+		assert.Equal("svc", entries[i]["service"])
+	}
+
+	assert.NotContains(entries[0], "shard")
+	assert.Equal(7.0, entries[1]["shard"])
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			l.UpdateLoggerContext(func(zc zerolog.Context) zerolog.Context {
+				return zc.Int("worker"+strconv.Itoa(i), i)
+			})
+		}(i)
+		go func() {
+			defer wg.Done()
+			l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		}()
+	}
+
+	wg.Wait()
+	buf.Reset()
+	l.Info(context.Background(), "last")
+	entry := map[string]any{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	for i := 0; i < 4; i++ {
+		assert.Equal(float64(i), entry["worker"+strconv.Itoa(i)])
+	}
+}
+
+func TestGormLoggerDisableEvents(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithDebug(sink.factory).WithInfo(sink.factory).WithWarn(sink.factory).WithError(sink.factory)
+	l.SetLogLevel(Debug)
+	l.SlowThreshold(time.Millisecond)
+	l.OverloadProtection(OverloadConfig{MaxRate: 1, Window: time.Nanosecond})
+	l.DisableEvents(true)
+	var errs, slow int
+	l.Use(func(_ context.Context, rec *TraceRecord) bool {
+		if rec.Err != nil {
+			errs++
+		}
+
+		if rec.Elapsed > time.Millisecond {
+			slow++
+		}
+
+		return true
+	})
+
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 2", 1 }, nil)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 3", 1 }, errors.New("test"))
+	l.Info(context.Background(), "test")
+	l.Error(context.Background(), "test")
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: NewNopGormLogger()})
+	require.NoError(t, err)
+	require.NoError(t, l.Apply(db))
+
+	assert.Empty(sink.events)
+	assert.Equal(1, errs)
+	assert.Equal(1, slow)
+	assert.Equal(uint64(3), l.Stats().Traces)
+
+	l.DisableEvents(false)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("test"))
+	assert.NotEmpty(sink.events)
+}
@@ -0,0 +1,96 @@
+package gormzerolog
+
+import (
+	"sync"
+)
+
+// OtherLabel is the label value replacing values over the LimitMetricLabels limit.
+const OtherLabel = "other"
+
+// MetricLabels are values of metric labels of a traced statement.
+type MetricLabels struct {
+	Table     string
+	Operation string
+}
+
+// MetricsHook is called for every traced statement kept by interceptors,
+// including ones which are not logged, to feed metrics.
+type MetricsHook func(labels MetricLabels, rec *TraceRecord)
+
+// SetMetricsHook sets a hook feeding metrics.
+func (l *GormLogger) SetMetricsHook(hook MetricsHook) {
+	l.metricsHook = hook
+}
+
+// LimitMetricLabels limits the number of distinct values of each metric label
+// passed to the metrics hook. Once the limit is reached, new values are
+// replaced with OtherLabel and counted in Stats.CollapsedLabels. Zero limit
+// disables the limit.
+func (l *GormLogger) LimitMetricLabels(maxValues int) {
+	if maxValues <= 0 {
+		l.labelLimiter = nil
+		return
+	}
+
+	l.labelLimiter = &labelLimiter{
+		max:        maxValues,
+		tables:     make(map[string]struct{}, maxValues),
+		operations: make(map[string]struct{}, maxValues),
+	}
+}
+
+// labelLimiter remembers up to max values of each label.
+type labelLimiter struct {
+	mu         sync.Mutex
+	max        int
+	tables     map[string]struct{}
+	operations map[string]struct{}
+}
+
+// limit replaces unknown values over the limit with OtherLabel and returns
+// the number of replaced values.
+func (ll *labelLimiter) limit(labels *MetricLabels) (collapsed uint64) {
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+	for _, label := range []struct {
+		value *string
+		seen  map[string]struct{}
+	}{
+		{&labels.Table, ll.tables},
+		{&labels.Operation, ll.operations},
+	} {
+		if *label.value == "" {
+			continue
+		}
+
+		if _, ok := label.seen[*label.value]; ok {
+			continue
+		}
+
+		if len(label.seen) < ll.max {
+			label.seen[*label.value] = struct{}{}
+			continue
+		}
+
+		*label.value = OtherLabel
+		collapsed++
+	}
+
+	return collapsed
+}
+
+// observe passes the record to the metrics hook.
+func (l *GormLogger) observe(rec *TraceRecord) {
+	if l.metricsHook == nil {
+		return
+	}
+
+	labels := MetricLabels{Table: rec.Table, Operation: rec.Operation}
+	if l.labelLimiter != nil {
+		if collapsed := l.labelLimiter.limit(&labels); collapsed > 0 {
+			l.stats.collapsedLabels.Add(collapsed)
+		}
+	}
+
+	l.metricsHook(labels, rec)
+}
@@ -0,0 +1,72 @@
+package gormzerolog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormLoggerMetricsHook(t *testing.T) {
+	assert := assert.New(t)
+	l := NewGormLogger().WithInfo(func() Event { return &testingEvent{} })
+	var mu sync.Mutex
+	counts := map[MetricLabels]int{}
+	l.SetMetricsHook(func(labels MetricLabels, rec *TraceRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		counts[labels]++
+	})
+	trace := func(sql string) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	trace("SELECT * FROM users")
+	trace("DELETE FROM users")
+	assert.Equal(map[MetricLabels]int{{"users", "select"}: 1, {"users", "delete"}: 1}, counts)
+
+	clear(counts)
+	l.LimitMetricLabels(3)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				trace(fmt.Sprintf("SELECT * FROM tmp_%d", j))
+			}
+		}()
+	}
+
+	wg.Wait()
+	total := 0
+	var admitted string
+	for labels, n := range counts {
+		assert.Equal("select", labels.Operation)
+		total += n
+		if labels.Table != OtherLabel {
+			admitted = labels.Table
+		}
+	}
+
+	assert.Len(counts, 4)
+	assert.Equal(800, total)
+	assert.Equal(8*97, counts[MetricLabels{OtherLabel, "select"}])
+	assert.Equal(uint64(8*97), l.Stats().CollapsedLabels)
+	assert.Len(l.labelLimiter.tables, 3)
+
+	trace("UPDATE " + admitted + " SET a = 1")
+	trace("INSERT INTO " + admitted + " VALUES (1)")
+	trace("DELETE FROM " + admitted)
+	assert.Equal(1, counts[MetricLabels{admitted, "update"}])
+	assert.Equal(1, counts[MetricLabels{admitted, "insert"}])
+	assert.Equal(1, counts[MetricLabels{admitted, OtherLabel}])
+	assert.Equal(uint64(8*97+1), l.Stats().CollapsedLabels)
+
+	l.Use(func(context.Context, *TraceRecord) bool { return false })
+	trace("SELECT * FROM users")
+	assert.Zero(counts[MetricLabels{"users", "select"}])
+}
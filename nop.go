@@ -0,0 +1,29 @@
+package gormzerolog
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+type nopLogger struct{}
+
+// NewNopGormLogger creates a logger which discards everything. Its methods
+// neither call fc nor look up the caller and don't allocate, so it is
+// the recommended logger for benchmarks.
+func NewNopGormLogger() logger.Interface {
+	return nopLogger{}
+}
+
+func (l nopLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (nopLogger) Info(context.Context, string, ...any) {}
+
+func (nopLogger) Warn(context.Context, string, ...any) {}
+
+func (nopLogger) Error(context.Context, string, ...any) {}
+
+func (nopLogger) Trace(context.Context, time.Time, func() (string, int64), error) {}
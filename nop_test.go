@@ -0,0 +1,41 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm/logger"
+)
+
+func TestNopGormLogger(t *testing.T) {
+	assert := assert.New(t)
+	l := NewNopGormLogger().LogMode(logger.Info)
+	ctx := context.Background()
+	begin := time.Now()
+	err := errors.New("test")
+	called := false
+	fc := func() (string, int64) {
+		called = true
+		return "SELECT 1", 1
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		l.Trace(ctx, begin, fc, err)
+	})
+	assert.Zero(allocs)
+	assert.False(called)
+}
+
+func BenchmarkNopGormLogger(b *testing.B) {
+	l := NewNopGormLogger()
+	ctx := context.Background()
+	begin := time.Now()
+	fc := func() (string, int64) { return "SELECT 1", 1 }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Trace(ctx, begin, fc, nil)
+	}
+}
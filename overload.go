@@ -0,0 +1,123 @@
+package gormzerolog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// OverloadConfig configures the overload protection. When the trace rate
+// stays above MaxRate for Sustain, logging of successful traces is switched
+// off until the rate stays below RecoverRate for Sustain.
+type OverloadConfig struct {
+	// MaxRate is a trace rate per second which triggers the downgrade.
+	MaxRate float64
+	// RecoverRate is a trace rate per second below which logging is restored.
+	// Defaults to a half of MaxRate.
+	RecoverRate float64
+	// Sustain is a period the rate has to stay beyond the bounds. Defaults to Window.
+	Sustain time.Duration
+	// Window is a period the rate is measured over. Defaults to one second.
+	Window time.Duration
+}
+
+type overloadBreaker struct {
+	cfg OverloadConfig
+
+	tripped atomic.Bool
+	count   atomic.Int64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	beyondSince time.Time
+}
+
+func newOverloadBreaker(cfg OverloadConfig) *overloadBreaker {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Second
+	}
+
+	if cfg.Sustain <= 0 {
+		cfg.Sustain = cfg.Window
+	}
+
+	if cfg.RecoverRate <= 0 || cfg.RecoverRate > cfg.MaxRate {
+		cfg.RecoverRate = cfg.MaxRate / 2
+	}
+
+	return &overloadBreaker{cfg: cfg}
+}
+
+// observe counts a trace and returns the new state and true
+// if the state has been changed by this call.
+func (b *overloadBreaker) observe(now time.Time) (tripped bool, changed bool, rate float64) {
+	b.count.Add(1)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.windowStart.IsZero() {
+		b.windowStart = now
+		return b.tripped.Load(), false, 0
+	}
+
+	elapsed := now.Sub(b.windowStart)
+	if elapsed < b.cfg.Window {
+		return b.tripped.Load(), false, 0
+	}
+
+	rate = float64(b.count.Swap(0)) / elapsed.Seconds()
+	b.windowStart = now
+	tripped = b.tripped.Load()
+	beyond := rate > b.cfg.MaxRate
+	if tripped {
+		beyond = rate < b.cfg.RecoverRate
+	}
+
+	if !beyond {
+		b.beyondSince = time.Time{}
+		return tripped, false, rate
+	}
+
+	if b.beyondSince.IsZero() {
+		b.beyondSince = now.Add(-elapsed)
+	}
+
+	if now.Sub(b.beyondSince) < b.cfg.Sustain {
+		return tripped, false, rate
+	}
+
+	b.beyondSince = time.Time{}
+	b.tripped.Store(!tripped)
+	return !tripped, true, rate
+}
+
+// OverloadProtection enables automatic switching off of successful trace
+// logging under sustained high query volume. Errors and slow queries are
+// still logged. The current state is reported by Stats.
+func (l *GormLogger) OverloadProtection(cfg OverloadConfig) {
+	l.overload = newOverloadBreaker(cfg)
+}
+
+// overloaded counts the trace and reports whether successful traces
+// should be suppressed, logging a notice on state transitions.
+func (l *GormLogger) overloaded(now time.Time) bool {
+	if l.overload == nil {
+		return false
+	}
+
+	tripped, changed, rate := l.overload.observe(now)
+	if !changed {
+		return tripped
+	}
+
+	l.stats.overloadTransitions.Add(1)
+	if tripped {
+		l.log(context.Background(), logger.Warn, "%s", overloadNotice(l.literals.OverloadSuspended, rate, l.overload.cfg.MaxRate))
+	} else {
+		l.log(context.Background(), logger.Info, "%s", overloadNotice(l.literals.OverloadResumed, rate, l.overload.cfg.RecoverRate))
+	}
+
+	return tripped
+}
@@ -0,0 +1,69 @@
+package gormzerolog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormLoggerOverloadProtection(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithWarn(warnSink.factory).WithClock(clock)
+	l.SlowThreshold(0)
+	l.OverloadProtection(OverloadConfig{MaxRate: 10, RecoverRate: 5, Sustain: time.Second * 2})
+
+	drive := func(rate, seconds int) {
+		for i := 0; i < rate*seconds; i++ {
+			clock.Advance(time.Second / time.Duration(rate))
+			l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		}
+	}
+
+	countInfo := func(substr string) int {
+		n := 0
+		for _, msg := range infoSink.messages() {
+			if strings.Contains(msg, substr) {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	drive(8, 5)
+	assert.False(l.Stats().Overloaded)
+	assert.Empty(warnSink.messages())
+	assert.Equal(40, countInfo("SELECT 1"))
+
+	drive(20, 1)
+	assert.False(l.Stats().Overloaded, "the rate should be sustained")
+	drive(20, 2)
+	assert.True(l.Stats().Overloaded)
+	assert.Equal(uint64(1), l.Stats().OverloadTransitions)
+	require.Len(t, warnSink.messages(), 1)
+	assert.Contains(warnSink.messages()[0], "suspended")
+
+	traced := countInfo("SELECT 1")
+	drive(20, 5)
+	drive(8, 5)
+	assert.True(l.Stats().Overloaded, "the rate should drop below the recovery rate")
+	assert.Equal(traced, countInfo("SELECT 1"))
+	assert.Len(warnSink.messages(), 1)
+
+	drive(2, 3)
+	assert.False(l.Stats().Overloaded)
+	assert.Equal(uint64(2), l.Stats().OverloadTransitions)
+	assert.Equal(1, countInfo("resumed"))
+	assert.Equal(uint64(8*5+20*8+8*5+2*3), l.Stats().Traces)
+
+	traced = countInfo("SELECT 1")
+	drive(2, 1)
+	assert.Equal(traced+2, countInfo("SELECT 1"))
+}
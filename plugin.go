@@ -0,0 +1,114 @@
+package gormzerolog
+
+import (
+	"context"
+	"runtime/debug"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	pluginName = "gormzerolog"
+	modulePath = "github.com/vitaliy-art/gorm-zerolog"
+	gormPath   = "gorm.io/gorm"
+)
+
+// Plugin is a GORM plugin attaching a GormLogger to a database.
+type Plugin struct {
+	logger *GormLogger
+}
+
+// NewPlugin creates a new GORM plugin for the logger.
+func NewPlugin(l *GormLogger) *Plugin {
+	return &Plugin{logger: l}
+}
+
+// Name returns the plugin name.
+func (p *Plugin) Name() string {
+	return pluginName
+}
+
+// Initialize sets the logger as the database logger and logs the banner.
+// Database info not set with WithDatabaseInfo is filled from the dialector
+// in traces of statements of the database, so the logger can be attached to
+// several databases. Callbacks are registered to log table, model and
+// operation of statements from their metadata instead of parsing SQL, and to
+// measure the time spent in the database apart from other callbacks.
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	if err := registerDialectorInfo(db); err != nil {
+		return err
+	}
+
+	if err := p.logger.registerStatementCallbacks(db); err != nil {
+		return err
+	}
+
+	db.Logger = p.logger
+	p.logger.logBanner(db)
+	return nil
+}
+
+// Apply attaches the logger to the database using the Plugin.
+func (l *GormLogger) Apply(db *gorm.DB) error {
+	return db.Use(NewPlugin(l))
+}
+
+// DisableBanner sets a flag for suppressing the banner logged on attach.
+func (l *GormLogger) DisableBanner(b bool) {
+	l.disableBanner = b
+}
+
+func (l *GormLogger) logBanner(db *gorm.DB) {
+	if l.disableBanner || l.level() <= logger.Silent {
+		return
+	}
+
+	event := l.buildEvent(context.Background(), logger.Info, true)
+	if event == nil {
+		return
+	}
+
+	if db.Dialector != nil {
+		event = l.dialectorInfo(databaseInfoFromDialector(db.Dialector)).attach(event)
+		event = event.Str("dialect", db.Dialector.Name())
+	}
+
+	event = event.
+		Str("level", LevelName(l.level())).
+		Str("slow_threshold", l.threshold().String()).
+		Str("ddl_level", LevelName(l.ddlLevel)).
+		Any("async", l.async != nil && l.async.isRunning()).
+		Any("tx_buffer", l.txBufferSize).
+		Any("sampling", l.sampler != nil || len(l.samplers) > 0).
+		Any("overload_protection", l.overload != nil).
+		Any("caller", !l.disableCaller)
+	if v := moduleVersion(gormPath); v != "" {
+		event = event.Str("gorm_version", v)
+	}
+
+	if v := moduleVersion(modulePath); v != "" {
+		event = event.Str("version", v)
+	}
+
+	event.Msgf("%s", l.literals.Banner)
+}
+
+func moduleVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	if info.Main.Path == path {
+		return info.Main.Version
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			return dep.Version
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,49 @@
+package gormzerolog
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestPluginBanner(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory)
+		l.AdditionalData = map[string]string{"service": "test"}
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, l.Apply(db))
+		assert.Same(l, db.Logger)
+		require.Len(t, sink.events, 1)
+		e := sink.events[0]
+		assert.Equal("gorm zerolog logger attached", e.msg)
+		assert.Equal("sqlite", e.added["dialect"])
+		assert.Equal("info", e.added["level"])
+		assert.Equal("200ms", e.added["slow_threshold"])
+		assert.Equal("info", e.added["ddl_level"])
+		assert.Equal(false, e.fields["async"])
+		assert.Equal(0, e.fields["tx_buffer"])
+		assert.Equal(false, e.fields["sampling"])
+		assert.Equal(false, e.fields["overload_protection"])
+		assert.NotEmpty(e.added["gorm_version"])
+		assert.Equal("test", e.added["service"])
+
+		assert.ErrorIs(l.Apply(db), gorm.ErrRegistered)
+		assert.Len(sink.events, 1)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory)
+		l.DisableBanner(true)
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, db.Use(NewPlugin(l)))
+		assert.Empty(t, sink.events)
+	})
+}
@@ -0,0 +1,161 @@
+package gormzerolog
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var statementCounter atomic.Uint64
+
+// LogBeforeExecution enables logging of statements right before they are sent
+// to the database at the given level, so a statement which never completes is
+// still logged. The events carry the stmt_id field, which is logged by the
+// trace of the statement too, so the pair can be joined. The SQL is logged
+// with placeholders instead of parameter values and truncated like in traces.
+// Samplers and OnlyLogMatching apply to the events. Statements are logged only
+// if the logger is attached with the Plugin. logger.Silent disables logging,
+// which is the default.
+func (l *GormLogger) LogBeforeExecution(logLevel logger.LogLevel) {
+	l.preExecLevel = logLevel
+}
+
+// tracksStatements reports whether statements get IDs and are executed
+// through a preExecPool.
+func (l *GormLogger) tracksStatements() bool {
+	return l.preExecLevel > logger.Silent || l.watchesInFlight()
+}
+
+// preExecPool is a connection pool logging statements before they are executed.
+type preExecPool struct {
+	gorm.ConnPool
+	l *GormLogger
+}
+
+func (p *preExecPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	p.l.logBeforeExecution(ctx, query)
+	return p.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (p *preExecPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	p.l.logBeforeExecution(ctx, query)
+	return p.ConnPool.QueryContext(ctx, query, args...)
+}
+
+func (p *preExecPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	p.l.logBeforeExecution(ctx, query)
+	return p.ConnPool.QueryRowContext(ctx, query, args...)
+}
+
+// newStatementID returns a process-wide unique statement ID.
+func newStatementID() string {
+	return strconv.FormatUint(statementCounter.Add(1), 10)
+}
+
+// wrapConnPool makes the statement executed through a preExecPool.
+// Statements of associations inherit the pool of their parent.
+func (l *GormLogger) wrapConnPool(db *gorm.DB, info *statementInfo) {
+	if db.DryRun {
+		return
+	}
+
+	if _, ok := db.Statement.ConnPool.(*preExecPool); ok {
+		return
+	}
+
+	info.pool = db.Statement.ConnPool
+	db.Statement.ConnPool = &preExecPool{ConnPool: info.pool, l: l}
+}
+
+// unwrapConnPool restores the pool replaced by wrapConnPool.
+func unwrapConnPool(db *gorm.DB, info *statementInfo) {
+	if info.pool == nil {
+		return
+	}
+
+	if p, ok := db.Statement.ConnPool.(*preExecPool); ok && p.ConnPool == info.pool {
+		db.Statement.ConnPool = info.pool
+	}
+
+	info.pool = nil
+}
+
+func (l *GormLogger) logBeforeExecution(ctx context.Context, query string) {
+	info := statementInfoFromContext(ctx)
+	if info == nil {
+		return
+	}
+
+	if info.sqlComment != "" {
+		query = strings.Replace(query, " "+info.sqlComment, "", 1)
+	}
+
+	if info.watch != nil {
+		info.watch.executing(query)
+	}
+
+	if l.preExecLevel <= logger.Silent {
+		return
+	}
+
+	rec := &TraceRecord{
+		SQL:         query,
+		Table:       info.table,
+		Operation:   info.operation,
+		Model:       info.model,
+		StatementID: info.id,
+	}
+
+	if rec.Operation == "" {
+		rec.Operation = sqlOperation(query)
+	}
+
+	if rec.Table == "" {
+		rec.Table = sqlTable(query)
+	}
+
+	if !l.matches(rec) {
+		return
+	}
+
+	event := l.newEventWith(ctx, l.preExecLevel, true)
+	if event == nil {
+		return
+	}
+
+	l.logStatement(event, rec, l.literals.BeforeExecution)
+}
+
+// logStatement logs the statement of a record, which has not completed yet,
+// with the message.
+func (l *GormLogger) logStatement(event Event, rec *TraceRecord, message string) {
+	event = event.Str(l.fieldNames.StatementID, rec.StatementID).Str(l.fieldNames.Operation, rec.Operation)
+	if rec.Table != "" {
+		event = event.Str(l.fieldNames.Table, rec.Table)
+	}
+
+	if rec.Model != "" {
+		event = event.Str(l.fieldNames.Model, rec.Model)
+	}
+
+	sql, truncated := truncateSQL(rec.SQL, l.sqlLimit)
+	if truncated {
+		event = event.Any("sql_truncated", true)
+	}
+
+	if l.sqlPlacement != OwnLine || sql == "" {
+		if sql != "" {
+			event = event.Str(l.fieldNames.SQL, sql)
+		}
+
+		event.Msgf("%s", message)
+		return
+	}
+
+	event.Msgf("%s\n%s", message, sql)
+}
@@ -0,0 +1,73 @@
+package gormzerolog
+
+import (
+	"io"
+	"os"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm/logger"
+)
+
+// ProductionSQLLimit is the limit of the logged SQL set by NewProductionGormLogger.
+const ProductionSQLLimit = 4 << 10
+
+// NewDevelopmentGormLogger creates a logger for local development writing
+// human-readable events to os.Stderr with NewConsoleGormLogger. It logs all
+// statements at logger.Info with the caller and the SQL on its own line.
+// Options are applied last and may override any of the settings.
+func NewDevelopmentGormLogger(opts ...func(*GormLogger)) *GormLogger {
+	return NewDevelopmentGormLoggerTo(os.Stderr, opts...)
+}
+
+// NewDevelopmentGormLoggerTo is NewDevelopmentGormLogger writing to out.
+func NewDevelopmentGormLoggerTo(out io.Writer, opts ...func(*GormLogger)) *GormLogger {
+	l := NewConsoleGormLogger(out)
+	l.SetLogLevel(logger.Info)
+	l.DisableCaller(false)
+	l.SQLPlacement(OwnLine)
+	return applyOptions(l, opts)
+}
+
+// NewProductionGormLogger creates a logger writing JSON events with timestamps
+// to w. It logs errors and slow queries at logger.Warn without the caller
+// lookup. The SQL is logged in the sql field with placeholders instead of
+// parameter values and truncated to ProductionSQLLimit bytes.
+// Options are applied last and may override any of the settings.
+func NewProductionGormLogger(w io.Writer, opts ...func(*GormLogger)) *GormLogger {
+	zl := zerolog.New(w).With().Timestamp().Logger()
+	l := NewGormLogger()
+	l.base.logger.Store(&zl)
+	l.SetLogLevel(logger.Warn)
+	l.DisableCaller(true)
+	l.SQLPlacement(Inline)
+	l.ParameterizedQueries(true)
+	l.TruncateSQL(ProductionSQLLimit)
+	return applyOptions(l, opts)
+}
+
+// NewFromConfig creates a logger with the settings of the GORM default logger
+// config, so it can replace logger.New(writer, cfg) in one line. The level,
+// the slow threshold and the flags mean the same as in GORM. With Colorful
+// set, events are written to os.Stdout with NewConsoleGormLogger, like GORM
+// does, otherwise with the global zerolog logger.
+// Options are applied last and may override any of the settings.
+func NewFromConfig(cfg logger.Config, opts ...func(*GormLogger)) *GormLogger {
+	l := NewGormLogger()
+	if cfg.Colorful {
+		l = NewConsoleGormLogger(os.Stdout)
+	}
+
+	l.SetLogLevel(cfg.LogLevel)
+	l.SlowThreshold(cfg.SlowThreshold)
+	l.IgnoreRecordNotFoundError(cfg.IgnoreRecordNotFoundError)
+	l.ParameterizedQueries(cfg.ParameterizedQueries)
+	return applyOptions(l, opts)
+}
+
+func applyOptions(l *GormLogger, opts []func(*GormLogger)) *GormLogger {
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
@@ -0,0 +1,30 @@
+package gormzerolog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm/logger"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	assert := assert.New(t)
+	l := NewFromConfig(logger.Config{
+		SlowThreshold:             time.Second,
+		LogLevel:                  logger.Warn,
+		IgnoreRecordNotFoundError: true,
+		ParameterizedQueries:      true,
+	})
+	assert.Equal(logger.Warn, l.level())
+	assert.Equal(time.Second, l.threshold())
+	assert.True(l.ignoreRecordNotFoundErr)
+	assert.True(l.parameterizedQueries)
+	assert.Nil(l.base.logger.Load(), "the global logger should be used")
+
+	l = NewFromConfig(logger.Config{Colorful: true}, func(l *GormLogger) { l.SlowThreshold(time.Minute) })
+	assert.Equal(logger.LogLevel(0), l.level())
+	assert.Equal(time.Minute, l.threshold())
+	assert.False(l.ignoreRecordNotFoundErr)
+	assert.NotNil(l.base.logger.Load(), "the console logger should be used")
+}
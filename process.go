@@ -0,0 +1,33 @@
+package gormzerolog
+
+import "os"
+
+// hostname is replaced in tests.
+var hostname = os.Hostname
+
+// ProcessInfo describes the service the process runs.
+type ProcessInfo struct {
+	Service string
+	Version string
+}
+
+// WithProcessInfo returns a copy of the logger adding service,
+// service_version, hostname and pid fields to every event as static fields.
+// The hostname and pid are captured once. Empty values, including
+// the hostname if it can't be determined, are omitted.
+func (l *GormLogger) WithProcessInfo(info ProcessInfo) *GormLogger {
+	fields := map[string]any{"pid": os.Getpid()}
+	if host, err := hostname(); err == nil && host != "" {
+		fields["hostname"] = host
+	}
+
+	if info.Service != "" {
+		fields["service"] = info.Service
+	}
+
+	if info.Version != "" {
+		fields["service_version"] = info.Version
+	}
+
+	return l.withStaticFields(fields)
+}
@@ -0,0 +1,69 @@
+package gormzerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormLoggerWithProcessInfo(t *testing.T) {
+	pid := os.Getpid()
+	t.Run("built-in", func(t *testing.T) {
+		assert := assert.New(t)
+		buf := &bytes.Buffer{}
+		globalLogger := log.Logger
+		log.Logger = zerolog.New(buf)
+		defer func() { log.Logger = globalLogger }()
+
+		l := NewGormLogger().WithProcessInfo(ProcessInfo{Service: "orders", Version: "1.2.3"})
+		host, _ := os.Hostname()
+		l.SetLogLevel(Debug)
+		l.DDLLevel(Debug)
+		l.SlowThreshold(time.Millisecond)
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "CREATE TABLE t (id int)", 0 }, nil)
+		l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("test"))
+
+		levels := map[string]bool{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			entry := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			assert.Equal("orders", entry["service"])
+			assert.Equal("1.2.3", entry["service_version"])
+			assert.Equal(host, entry["hostname"])
+			assert.Equal(float64(pid), entry["pid"])
+			levels[entry["level"].(string)] = true
+		}
+
+		assert.Equal(map[string]bool{"debug": true, "info": true, "warn": true, "error": true}, levels)
+	})
+
+	t.Run("omitted", func(t *testing.T) {
+		defer func(h func() (string, error)) { hostname = h }(hostname)
+		hostname = func() (string, error) { return "", errors.New("test") }
+		sink := &testingSink{}
+		l := NewGormLogger().WithProcessInfo(ProcessInfo{Service: "orders"}).WithWarn(sink.factory)
+		l.Warn(context.Background(), "test")
+		require.Len(t, sink.events, 1)
+		assert.Equal(t, map[string]any{"service": "orders", "pid": pid}, sink.events[0].fields)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		sink := &testingSink{}
+		l := NewGormLogger().WithProcessInfo(ProcessInfo{Version: "1.2.3"}).WithError(sink.factory)
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("test"))
+		require.Len(t, sink.events, 1)
+		assert.Equal(t, "1.2.3", sink.events[0].fields["service_version"])
+		assert.NotContains(t, sink.events[0].fields, "service")
+	})
+}
@@ -0,0 +1,117 @@
+package gormzerolog
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// RegressionConfig configures the detection of latency regressions.
+// A rolling average of the latency of every statement shape is kept as its
+// baseline, and a statement slower than Factor times its baseline is flagged.
+type RegressionConfig struct {
+	// Factor is a ratio of the latency to the baseline which is a regression. Default is 3.
+	Factor float64
+	// MinSamples is a number of samples of a shape needed before it is checked. Default is 20.
+	MinSamples int
+	// Alpha is a smoothing factor of the rolling average, between 0 and 1. Default is 0.1.
+	Alpha float64
+	// Size is the number of most recently seen shapes remembered. Default is 1024.
+	Size int
+}
+
+type latencyBaseline struct {
+	key     uint64
+	samples int
+	average float64
+}
+
+// regressionDetector keeps latency baselines of a bounded number of statement shapes.
+type regressionDetector struct {
+	cfg       RegressionConfig
+	mu        sync.Mutex
+	order     *list.List
+	baselines map[uint64]*list.Element
+}
+
+func newRegressionDetector(cfg RegressionConfig) *regressionDetector {
+	if cfg.Factor <= 1 {
+		cfg.Factor = 3
+	}
+
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = 20
+	}
+
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		cfg.Alpha = 0.1
+	}
+
+	if cfg.Size <= 0 {
+		cfg.Size = 1024
+	}
+
+	return &regressionDetector{
+		cfg:       cfg,
+		order:     list.New(),
+		baselines: make(map[uint64]*list.Element, cfg.Size),
+	}
+}
+
+// observe checks the latency against the baseline of the shape and adds it
+// to the baseline. It returns the baseline if the latency is a regression.
+func (d *regressionDetector) observe(key uint64, elapsed time.Duration) (baseline time.Duration, regressed bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, ok := d.baselines[key]
+	if !ok {
+		if d.order.Len() >= d.cfg.Size {
+			oldest := d.order.Back()
+			delete(d.baselines, oldest.Value.(*latencyBaseline).key)
+			d.order.Remove(oldest)
+		}
+
+		e = d.order.PushFront(&latencyBaseline{key: key})
+		d.baselines[key] = e
+	} else {
+		d.order.MoveToFront(e)
+	}
+
+	b := e.Value.(*latencyBaseline)
+	observed := float64(elapsed)
+	if b.samples >= d.cfg.MinSamples && observed > b.average*d.cfg.Factor {
+		baseline, regressed = time.Duration(b.average), true
+	}
+
+	if b.samples == 0 {
+		b.average = observed
+	} else {
+		b.average += d.cfg.Alpha * (observed - b.average)
+	}
+
+	b.samples++
+	return baseline, regressed
+}
+
+// DetectLatencyRegressions enables the detection of latency regressions by the
+// config. Successful statements slower than their baseline by the factor are
+// logged at warn level with latency_regression, latency_baseline and
+// latency_observed fields. Flagged latencies are added to the baseline too,
+// so it adapts to a lasting change.
+func (l *GormLogger) DetectLatencyRegressions(cfg RegressionConfig) {
+	l.regressions = newRegressionDetector(cfg)
+}
+
+func (l *GormLogger) checkRegression(rec *TraceRecord) {
+	if l.regressions == nil || rec.Err != nil {
+		return
+	}
+
+	if baseline, ok := l.regressions.observe(fingerprint(rec.SQL), rec.Elapsed); ok {
+		rec.LatencyRegression = true
+		rec.LatencyBaseline = baseline
+		rec.escalate(logger.Warn)
+	}
+}
@@ -0,0 +1,83 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormLoggerDetectLatencyRegressions(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().
+		WithInfo(infoSink.factory).
+		WithWarn(warnSink.factory).
+		WithError(errorSink.factory).
+		WithClock(clock)
+	l.SlowThreshold(time.Second)
+	l.DetectLatencyRegressions(RegressionConfig{Factor: 3, MinSamples: 5, Alpha: 0.1})
+	trace := func(sql string, elapsed time.Duration, err error) bool {
+		warns := len(warnSink.events)
+		l.Trace(context.Background(), clock.Now().Add(-elapsed), func() (string, int64) { return sql, 1 }, err)
+		return len(warnSink.events) > warns
+	}
+
+	t.Run("warm-up", func(t *testing.T) {
+		for i, elapsed := range []int{3, 60, 3, 3, 3} {
+			assert.False(trace("SELECT * FROM users WHERE id = 1", time.Duration(elapsed)*time.Millisecond, nil), i)
+		}
+	})
+
+	t.Run("degradation", func(t *testing.T) {
+		var alerts []int
+		for i := 0; i < 20; i++ {
+			elapsed := 3 * time.Millisecond
+			if i >= 10 {
+				elapsed = 20 * time.Millisecond
+			}
+
+			if trace("SELECT * FROM orders WHERE id = 1", elapsed, nil) {
+				alerts = append(alerts, i)
+			}
+		}
+
+		// the baseline rises to 4.7ms, 6.23ms and 7.6ms after the alerts
+		assert.Equal([]int{10, 11, 12}, alerts)
+		first := warnSink.events[len(warnSink.events)-3]
+		assert.Equal(true, first.fields["latency_regression"])
+		assert.Equal(3*time.Millisecond, first.fields["latency_baseline"])
+		assert.Equal(20*time.Millisecond, first.fields["latency_observed"])
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		warns := len(warnSink.events)
+		for i := 0; i < 10; i++ {
+			trace("SELECT * FROM items WHERE id = 1", time.Millisecond, errors.New("test"))
+		}
+
+		trace("SELECT * FROM items WHERE id = 1", 100*time.Millisecond, errors.New("test"))
+		assert.Len(warnSink.events, warns)
+		assert.Len(errorSink.events, 11)
+	})
+}
+
+func TestRegressionDetectorBounded(t *testing.T) {
+	d := newRegressionDetector(RegressionConfig{MinSamples: 1, Size: 2})
+	for key := uint64(1); key <= 3; key++ {
+		d.observe(key, time.Millisecond)
+	}
+
+	require.Len(t, d.baselines, 2)
+	assert.NotContains(t, d.baselines, uint64(1))
+	_, regressed := d.observe(1, time.Second)
+	assert.False(t, regressed)
+	_, regressed = d.observe(3, time.Second)
+	assert.True(t, regressed)
+}
@@ -0,0 +1,44 @@
+package gormzerolog
+
+type rowsFieldNames struct {
+	returned string
+	affected string
+	rows     string
+}
+
+var defaultRowsFieldNames = rowsFieldNames{
+	returned: "rows_returned",
+	affected: "rows_affected",
+	rows:     "rows",
+}
+
+// name returns the field name for the number of rows of the statement.
+func (n rowsFieldNames) name(sql string) string {
+	switch {
+	case isWrite(sql):
+		return n.affected
+	case isRead(sql):
+		return n.returned
+	default:
+		return n.rows
+	}
+}
+
+// RowsFieldNames sets the names of the fields with the number of rows of
+// a trace: rows is used for all statements, and in addition returned for
+// queries and affected for statements modifying rows. Empty names keep their
+// current values. The number is logged as an integer. If it is unknown,
+// the fields are omitted and the rows_unknown field is logged instead.
+func (l *GormLogger) RowsFieldNames(returned, affected, rows string) {
+	if returned != "" {
+		l.rowsFields.returned = returned
+	}
+
+	if affected != "" {
+		l.rowsFields.affected = affected
+	}
+
+	if rows != "" {
+		l.rowsFields.rows = rows
+	}
+}
@@ -0,0 +1,68 @@
+package gormzerolog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormLoggerRowsFields(t *testing.T) {
+	for _, tc := range []struct {
+		sql   string
+		rows  int64
+		field string
+	}{
+		{"SELECT * FROM users", 3, "rows_returned"},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", 1, "rows_returned"},
+		{"INSERT INTO users (name) VALUES ('a')", 1, "rows_affected"},
+		{"UPDATE users SET name = 'b'", 5, "rows_affected"},
+		{"DELETE FROM users", 2, "rows_affected"},
+		{"CALL refresh_users()", 0, "rows"},
+		{"CREATE TABLE t (id int)", 0, "rows"},
+		{"SELECT * FROM users", -1, ""},
+	} {
+		t.Run(tc.sql, func(t *testing.T) {
+			infoSink := &testingSink{}
+			l := NewGormLogger().WithInfo(infoSink.factory)
+			l.Trace(context.Background(), time.Now(), func() (string, int64) { return tc.sql, tc.rows }, nil)
+			require.Len(t, infoSink.events, 1)
+			fields := infoSink.events[0].fields
+			for _, name := range []string{"rows_returned", "rows_affected"} {
+				if name == tc.field {
+					assert.Equal(t, tc.rows, fields[name])
+				} else {
+					assert.NotContains(t, fields, name)
+				}
+			}
+
+			if tc.rows == -1 {
+				assert.NotContains(t, fields, "rows")
+				assert.Equal(t, true, fields["rows_unknown"])
+			} else {
+				assert.Equal(t, tc.rows, fields["rows"])
+				assert.NotContains(t, fields, "rows_unknown")
+			}
+		})
+	}
+}
+
+func TestGormLoggerRowsFieldNames(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory)
+	l.RowsFieldNames("returned", "", "count")
+	for _, sql := range []string{"SELECT 1", "DELETE FROM users", "VACUUM"} {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	require.Len(t, infoSink.events, 3)
+	assert.Equal(int64(1), infoSink.events[0].fields["returned"])
+	assert.Equal(int64(1), infoSink.events[0].fields["count"])
+	assert.Equal(int64(1), infoSink.events[1].fields["rows_affected"])
+	assert.Equal(int64(1), infoSink.events[1].fields["count"])
+	assert.Equal(int64(1), infoSink.events[2].fields["count"])
+	assert.NotContains(infoSink.events[2].fields, "rows")
+}
@@ -0,0 +1,66 @@
+package gormzerolog
+
+import (
+	"maps"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// settings holds the configuration which may be changed while logging.
+// Reads and updates are lock-free.
+type settings struct {
+	level         atomic.Int64
+	slowThreshold atomic.Int64
+	data          atomic.Pointer[map[string]string]
+}
+
+func newSettings(logLevel logger.LogLevel, slowThreshold time.Duration) *settings {
+	s := &settings{}
+	s.level.Store(int64(logLevel))
+	s.slowThreshold.Store(int64(slowThreshold))
+	return s
+}
+
+func (s *settings) clone() *settings {
+	c := newSettings(logger.LogLevel(s.level.Load()), time.Duration(s.slowThreshold.Load()))
+	c.data.Store(s.data.Load())
+	return c
+}
+
+// SetLogLevel sets a log level value. It is safe to call while logging.
+func (l *GormLogger) SetLogLevel(logLevel logger.LogLevel) {
+	l.settings.level.Store(int64(logLevel))
+}
+
+// SlowThreshold sets a slow threshold level value. It is safe to call while
+// logging.
+func (l *GormLogger) SlowThreshold(slowThreshold time.Duration) {
+	l.settings.slowThreshold.Store(int64(slowThreshold))
+}
+
+// SetAdditionalData replaces the additional data added to every event like
+// AdditionalData, taking precedence over it. Unlike modifying AdditionalData,
+// it is safe to call while logging. The map is copied.
+func (l *GormLogger) SetAdditionalData(data map[string]string) {
+	data = maps.Clone(data)
+	l.settings.data.Store(&data)
+}
+
+func (l *GormLogger) level() logger.LogLevel {
+	return logger.LogLevel(l.settings.level.Load())
+}
+
+func (l *GormLogger) threshold() time.Duration {
+	return time.Duration(l.settings.slowThreshold.Load())
+}
+
+// additionalData returns the data set with SetAdditionalData.
+func (l *GormLogger) additionalData() map[string]string {
+	if data := l.settings.data.Load(); data != nil {
+		return *data
+	}
+
+	return nil
+}
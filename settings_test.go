@@ -0,0 +1,56 @@
+package gormzerolog
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLoggerSettings(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithWarn(sink.factory)
+	l.AdditionalData = map[string]string{"service": "svc", "region": "us"}
+	data := map[string]string{"region": "eu"}
+	l.SetAdditionalData(data)
+	data["region"] = "changed"
+
+	l.Info(context.Background(), "test")
+	require.Len(t, sink.events, 1)
+	assert.Equal(map[string]string{"service": "svc", "region": "eu"}, sink.events[0].added)
+
+	c := l.LogMode(logger.Info).(*GormLogger)
+	l.SetAdditionalData(nil)
+	c.Info(context.Background(), "copy")
+	assert.Equal("eu", sink.events[1].added["region"])
+}
+
+func TestGormLoggerSettingsConcurrent(t *testing.T) {
+	nop := func() Event { return &testingEvent{} }
+	l := NewGormLogger().WithInfo(nop).WithWarn(nop).WithError(nop)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+			}
+		}()
+	}
+
+	for i := 0; i < 200; i++ {
+		l.SetLogLevel(logger.LogLevel(i%4 + 1))
+		l.SlowThreshold(time.Duration(i) * time.Millisecond)
+		l.SetAdditionalData(map[string]string{"i": "x"})
+	}
+
+	wg.Wait()
+	assert.Equal(t, 199*time.Millisecond, l.threshold())
+	assert.Equal(t, logger.Info, l.level())
+}
@@ -0,0 +1,180 @@
+package gormzerolog
+
+import (
+	"cmp"
+	"container/heap"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// SlowStatement is a statement shape in the slowest statements report.
+type SlowStatement struct {
+	// SQL is the statement with literals replaced with "?".
+	SQL   string
+	Count int64
+	Max   time.Duration
+	Avg   time.Duration
+}
+
+type slowestEntry struct {
+	fp    uint64
+	sql   string
+	count int64
+	total time.Duration
+	max   time.Duration
+	index int
+}
+
+// slowestHeap is a min-heap of entries by their max elapsed time.
+type slowestHeap []*slowestEntry
+
+func (h slowestHeap) Len() int           { return len(h) }
+func (h slowestHeap) Less(i, j int) bool { return h[i].max < h[j].max }
+
+func (h slowestHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *slowestHeap) Push(x any) {
+	e := x.(*slowestEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *slowestHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+// slowestCollector keeps statistics of a bounded number of statement shapes
+// with the greatest max elapsed times.
+type slowestCollector struct {
+	mu       sync.Mutex
+	n        int
+	capacity int
+	entries  map[uint64]*slowestEntry
+	heap     slowestHeap
+}
+
+func newSlowestCollector(n int) *slowestCollector {
+	capacity := n * 4
+	return &slowestCollector{
+		n:        n,
+		capacity: capacity,
+		entries:  make(map[uint64]*slowestEntry, capacity),
+		heap:     make(slowestHeap, 0, capacity),
+	}
+}
+
+func (c *slowestCollector) add(sql string, elapsed time.Duration) {
+	normalized := normalizeSQL(sql)
+	fp := normalizedFingerprint(normalized)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[fp]; ok {
+		e.count++
+		e.total += elapsed
+		if elapsed > e.max {
+			e.max = elapsed
+			heap.Fix(&c.heap, e.index)
+		}
+
+		return
+	}
+
+	if len(c.heap) >= c.capacity {
+		if c.heap[0].max >= elapsed {
+			return
+		}
+
+		delete(c.entries, heap.Pop(&c.heap).(*slowestEntry).fp)
+	}
+
+	e := &slowestEntry{fp: fp, sql: normalized, count: 1, total: elapsed, max: elapsed}
+	heap.Push(&c.heap, e)
+	c.entries[fp] = e
+}
+
+func (c *slowestCollector) slowest() []SlowStatement {
+	c.mu.Lock()
+	statements := make([]SlowStatement, 0, len(c.heap))
+	for _, e := range c.heap {
+		statements = append(statements, SlowStatement{
+			SQL:   e.sql,
+			Count: e.count,
+			Max:   e.max,
+			Avg:   e.total / time.Duration(e.count),
+		})
+	}
+
+	c.mu.Unlock()
+	slices.SortFunc(statements, func(a, b SlowStatement) int {
+		if c := cmp.Compare(b.Max, a.Max); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.SQL, b.SQL)
+	})
+
+	return statements[:min(c.n, len(statements))]
+}
+
+// CollectSlowest enables collection of the n slowest statement shapes by max
+// elapsed time, reported by ReportSlowest and on Close. Memory is bounded by
+// keeping a few times n shapes, so counts and averages of shapes evicted and
+// seen again are collected since they were seen again. Zero n disables
+// the collection.
+func (l *GormLogger) CollectSlowest(n int) {
+	if n <= 0 {
+		l.slowest = nil
+		return
+	}
+
+	l.slowest = newSlowestCollector(n)
+}
+
+// Slowest returns the collected slowest statement shapes, the slowest first.
+func (l *GormLogger) Slowest() []SlowStatement {
+	if l.slowest == nil {
+		return nil
+	}
+
+	return l.slowest.slowest()
+}
+
+// ReportSlowest logs the collected slowest statement shapes with the event
+// in the slowest field, each with sql, count, max_ms and avg_ms.
+func (l *GormLogger) ReportSlowest(e Event) {
+	statements := l.Slowest()
+	report := make([]map[string]any, 0, len(statements))
+	for _, s := range statements {
+		report = append(report, map[string]any{
+			"sql":    s.SQL,
+			"count":  s.Count,
+			"max_ms": float64(s.Max.Nanoseconds()) / 1e6,
+			"avg_ms": float64(s.Avg.Nanoseconds()) / 1e6,
+		})
+	}
+
+	e.Any("slowest", report).Msgf("%s", l.literals.SlowestReport)
+}
+
+// reportSlowestOnClose logs the report at info level if the collection is enabled.
+func (l *GormLogger) reportSlowestOnClose() {
+	if l.slowest == nil {
+		return
+	}
+
+	if e := l.newEvent(logger.Info, false); e != nil {
+		l.ReportSlowest(e)
+	}
+}
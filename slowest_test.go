@@ -0,0 +1,61 @@
+package gormzerolog
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormLoggerCollectSlowest(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	sink := &testingSink{}
+	l := NewGormLogger().WithClock(clock).WithInfo(sink.factory).WithWarn(sink.factory)
+	l.DisableEvents(true)
+	trace := func(sql string, elapsed time.Duration) {
+		l.Trace(context.Background(), clock.Now().Add(-elapsed), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	trace("SELECT 1", time.Second)
+	assert.Nil(l.Slowest())
+
+	l.CollectSlowest(2)
+	for i := 0; i < 100; i++ {
+		// many fast shapes must not evict the slow ones
+		trace(fmt.Sprintf("SELECT * FROM tmp_%d", i), time.Millisecond)
+	}
+
+	trace("SELECT * FROM users WHERE id = 1", 10*time.Millisecond)
+	trace("SELECT * FROM users WHERE id = 2", 30*time.Millisecond)
+	trace("SELECT * FROM orders WHERE total > 100", 50*time.Millisecond)
+	trace("SELECT * FROM orders WHERE total > 200", 10*time.Millisecond)
+	trace("SELECT * FROM orders WHERE total > 300", 30*time.Millisecond)
+	for i := 0; i < 100; i++ {
+		trace(fmt.Sprintf("SELECT * FROM tmp_%d", i), time.Millisecond)
+	}
+
+	assert.Equal([]SlowStatement{
+		{SQL: "SELECT * FROM orders WHERE total > ?", Count: 3, Max: 50 * time.Millisecond, Avg: 30 * time.Millisecond},
+		{SQL: "SELECT * FROM users WHERE id = ?", Count: 2, Max: 30 * time.Millisecond, Avg: 20 * time.Millisecond},
+	}, l.Slowest())
+	assert.LessOrEqual(len(l.slowest.entries), 8)
+
+	l.DisableEvents(false)
+	report := &testingEvent{}
+	l.ReportSlowest(report)
+	assert.Equal("slowest statements", report.msg)
+	assert.Equal([]map[string]any{
+		{"sql": "SELECT * FROM orders WHERE total > ?", "count": int64(3), "max_ms": 50.0, "avg_ms": 30.0},
+		{"sql": "SELECT * FROM users WHERE id = ?", "count": int64(2), "max_ms": 30.0, "avg_ms": 20.0},
+	}, report.fields["slowest"])
+
+	sink.events = nil
+	require.NoError(t, l.Close())
+	require.Len(t, sink.events, 1)
+	assert.Equal("slowest statements", sink.events[0].msg)
+	assert.Len(sink.events[0].fields["slowest"], 2)
+}
@@ -0,0 +1,32 @@
+package gormzerolog
+
+import "context"
+
+// SpanExtractor returns the IDs of the tracing span active in the context,
+// ok is false if there is none.
+type SpanExtractor func(ctx context.Context) (traceID, spanID string, ok bool)
+
+// WithSpanExtractor returns a copy of the logger adding the trace_id and
+// span_id fields to every event of a context with an active span, so SQL
+// logs can be joined with distributed traces. The package doesn't depend on
+// OpenTelemetry, so the IDs are read by the extractor, e.g.:
+//
+//	func(ctx context.Context) (string, string, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return sc.TraceID().String(), sc.SpanID().String(), sc.IsValid()
+//	}
+func (l *GormLogger) WithSpanExtractor(extract SpanExtractor) *GormLogger {
+	c := l.clone()
+	c.spanExtractor = extract
+	return c
+}
+
+// addSpanFields adds the IDs of the span active in the context to the fields,
+// recovering from panics of the extractor.
+func (l *GormLogger) addSpanFields(ctx context.Context, fields map[string]any) {
+	defer func() { _ = recover() }()
+	if traceID, spanID, ok := l.spanExtractor(ctx); ok {
+		fields[l.fieldNames.TraceID] = traceID
+		fields[l.fieldNames.SpanID] = spanID
+	}
+}
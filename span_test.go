@@ -0,0 +1,33 @@
+package gormzerolog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type spanKey struct{}
+
+func TestGormLoggerWithSpanExtractor(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithSpanExtractor(func(ctx context.Context) (string, string, bool) {
+		ids, ok := ctx.Value(spanKey{}).([2]string)
+		return ids[0], ids[1], ok
+	})
+	ctx := context.WithValue(context.Background(), spanKey{}, [2]string{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"})
+
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	l.SetFieldNames(FieldNames{TraceID: "trace.id", SpanID: "span.id"})
+	l.Info(ctx, "test")
+	require.Len(t, sink.events, 3)
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", sink.events[0].fields["trace_id"])
+	assert.Equal("00f067aa0ba902b7", sink.events[0].fields["span_id"])
+	assert.NotContains(sink.events[1].fields, "trace_id")
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", sink.events[2].fields["trace.id"])
+	assert.Equal("00f067aa0ba902b7", sink.events[2].fields["span.id"])
+}
@@ -0,0 +1,541 @@
+package gormzerolog
+
+import (
+	"bytes"
+	"hash/fnv"
+	"strings"
+)
+
+var (
+	ddlKeywords = map[string]struct{}{
+		"CREATE":   {},
+		"ALTER":    {},
+		"DROP":     {},
+		"TRUNCATE": {},
+		"RENAME":   {},
+		"COMMENT":  {},
+		"PRAGMA":   {},
+		"SHOW":     {},
+		"DESCRIBE": {},
+	}
+
+	readKeywords = map[string]struct{}{
+		"SELECT": {},
+	}
+
+	writeKeywords = map[string]struct{}{
+		"INSERT":  {},
+		"UPDATE":  {},
+		"DELETE":  {},
+		"REPLACE": {},
+		"MERGE":   {},
+		"UPSERT":  {},
+	}
+
+	aggregateFunctions = map[string]struct{}{
+		"COUNT": {},
+		"SUM":   {},
+		"AVG":   {},
+		"MIN":   {},
+		"MAX":   {},
+	}
+
+	// introspectionMarkers are the catalog objects queried by GORM migrators.
+	introspectionMarkers = []string{
+		"sqlite_master",
+		"sqlite_schema",
+		"information_schema.",
+		"pg_catalog.",
+		"pg_indexes",
+		"pg_namespace",
+		"current_database()",
+		"database()",
+	}
+)
+
+// firstKeyword returns the first word of the statement in upper case,
+// skipping leading whitespace, comments and parentheses.
+func firstKeyword(sql string) string {
+	sql = skipSQLNoise(sql)
+	end := strings.IndexFunc(sql, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_')
+	})
+	if end < 0 {
+		end = len(sql)
+	}
+
+	return strings.ToUpper(sql[:end])
+}
+
+func skipSQLNoise(sql string) string {
+	for {
+		sql = strings.TrimLeft(sql, " \t\r\n(")
+		switch {
+		case strings.HasPrefix(sql, "--"):
+			i := strings.IndexByte(sql, '\n')
+			if i < 0 {
+				return ""
+			}
+
+			sql = sql[i+1:]
+		case strings.HasPrefix(sql, "/*"):
+			i := strings.Index(sql, "*/")
+			if i < 0 {
+				return ""
+			}
+
+			sql = sql[i+2:]
+		default:
+			return sql
+		}
+	}
+}
+
+// isDDL reports whether the statement is a schema definition statement or
+// an introspection query run by GORM migrators. Catalog objects are looked up
+// outside of string literals and comments.
+func isDDL(sql string) bool {
+	keyword := firstKeyword(sql)
+	if _, ok := ddlKeywords[keyword]; ok {
+		return true
+	}
+
+	if keyword != "SELECT" {
+		return false
+	}
+
+	lower := strings.ToLower(normalizeSQL(sql))
+	for _, marker := range introspectionMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statementKeyword returns the keyword of the main statement, looking past
+// common table expressions of WITH statements.
+func statementKeyword(sql string) string {
+	keyword := firstKeyword(sql)
+	if keyword != "WITH" {
+		return keyword
+	}
+
+	depth := 0
+	quote := byte(0)
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'):
+			word := firstKeyword(sql[i:])
+			if _, ok := readKeywords[word]; ok {
+				return word
+			}
+
+			if _, ok := writeKeywords[word]; ok {
+				return word
+			}
+
+			i += len(word) - 1
+		}
+	}
+
+	return keyword
+}
+
+// isRead reports whether the statement returns rows.
+func isRead(sql string) bool {
+	_, ok := readKeywords[statementKeyword(sql)]
+	return ok && !isDDL(sql)
+}
+
+// isWrite reports whether the statement modifies rows.
+func isWrite(sql string) bool {
+	_, ok := writeKeywords[statementKeyword(sql)]
+	return ok
+}
+
+// sqlOperation returns the lower case verb of the statement,
+// or "ddl" for schema definition statements.
+func sqlOperation(sql string) string {
+	if isDDL(sql) {
+		return "ddl"
+	}
+
+	return strings.ToLower(statementKeyword(sql))
+}
+
+// sqlTable returns the name of the first table the statement reads from or
+// writes to, or an empty string if it can't be determined, e.g. for subqueries.
+func sqlTable(sql string) string {
+	var after string
+	switch statementKeyword(sql) {
+	case "SELECT", "DELETE":
+		after = "FROM"
+	case "INSERT", "REPLACE", "MERGE", "UPSERT":
+		after = "INTO"
+	case "UPDATE":
+		after = "UPDATE"
+	default:
+		return ""
+	}
+
+	tokens := sqlTokens(sql)
+	for i, token := range tokens {
+		if strings.EqualFold(token, after) && i+1 < len(tokens) && tokens[i+1] != "(" {
+			return tokens[i+1]
+		}
+	}
+
+	return ""
+}
+
+// sqlTokens splits the statement into unquoted identifiers and keywords
+// outside of parentheses, skipping string literals and punctuation.
+// Dots join parts of qualified names, "(" marks the start of a subexpression.
+func sqlTokens(sql string) []string {
+	var tokens []string
+	var token strings.Builder
+	depth := 0
+	flush := func() {
+		if token.Len() > 0 {
+			tokens = append(tokens, token.String())
+			token.Reset()
+		}
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'':
+			flush()
+			end := strings.IndexByte(sql[i+1:], '\'')
+			if end < 0 {
+				return tokens
+			}
+
+			i += end + 1
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+
+			end := strings.IndexByte(sql[i+1:], closing)
+			if end < 0 {
+				return tokens
+			}
+
+			if depth == 0 {
+				token.WriteString(sql[i+1 : i+1+end])
+			}
+
+			i += end + 1
+		case c == '(':
+			flush()
+			if depth == 0 {
+				tokens = append(tokens, "(")
+			}
+
+			depth++
+		case c == ')':
+			flush()
+			depth--
+		case c == '.' || c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+			if depth == 0 {
+				token.WriteByte(c)
+			}
+		default:
+			flush()
+		}
+	}
+
+	flush()
+	return tokens
+}
+
+// maxInListSize returns the number of elements of the largest IN (...) list
+// of the statement. Commas in string literals and nested parentheses, e.g.
+// function calls, are not counted. Subqueries are not lists.
+func maxInListSize(sql string) int {
+	largest := 0
+	quote := byte(0)
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case isWordByte(c):
+			start := i
+			for i < len(sql) && isWordByte(sql[i]) {
+				i++
+			}
+
+			word := sql[start:i]
+			i--
+			if !strings.EqualFold(word, "IN") {
+				continue
+			}
+
+			rest := strings.TrimLeft(sql[i+1:], " \t\r\n")
+			if strings.HasPrefix(rest, "(") {
+				largest = max(largest, inListSize(rest[1:]))
+			}
+		}
+	}
+
+	return largest
+}
+
+// inListSize counts the elements of the list following its opening parenthesis.
+func inListSize(list string) int {
+	switch firstKeyword(list) {
+	case "SELECT", "WITH":
+		return 0
+	}
+
+	if strings.HasPrefix(strings.TrimLeft(list, " \t\r\n"), ")") {
+		return 0
+	}
+
+	size := 1
+	depth := 0
+	quote := byte(0)
+	for i := 0; i < len(list); i++ {
+		c := list[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return size
+			}
+
+			depth--
+		case c == ',' && depth == 0:
+			size++
+		}
+	}
+
+	return size
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// isUnboundedSelect reports whether the statement is a SELECT without
+// a top-level LIMIT, FETCH or TOP clause, which doesn't consist of
+// aggregates only.
+func isUnboundedSelect(sql string) bool {
+	if statementKeyword(sql) != "SELECT" {
+		return false
+	}
+
+	tokens := sqlTokens(sql)
+	for _, token := range tokens {
+		switch strings.ToUpper(token) {
+		case "LIMIT", "FETCH", "TOP":
+			return false
+		}
+	}
+
+	return !isAggregateOnly(sql, tokens)
+}
+
+// isAggregateOnly reports whether all the top-level projection items of
+// the SELECT are aggregate function calls and there is no GROUP BY clause.
+func isAggregateOnly(sql string, tokens []string) bool {
+	for i, token := range tokens {
+		if strings.EqualFold(token, "GROUP") && i+1 < len(tokens) && strings.EqualFold(tokens[i+1], "BY") {
+			return false
+		}
+	}
+
+	items := topLevelProjection(sql)
+	if len(items) == 0 {
+		return false
+	}
+
+	for _, item := range items {
+		keyword := firstKeyword(item)
+		if _, ok := aggregateFunctions[keyword]; !ok {
+			return false
+		}
+
+		if !strings.HasPrefix(strings.TrimLeft(skipSQLNoise(item)[len(keyword):], " \t\r\n"), "(") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// topLevelProjection returns the items of the projection of the main SELECT.
+func topLevelProjection(sql string) []string {
+	var items []string
+	depth := 0
+	quote := byte(0)
+	start := -1
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && c == ',' && start >= 0:
+			items = append(items, sql[start:i])
+			start = i + 1
+		case depth == 0 && isWordByte(c):
+			end := i
+			for end < len(sql) && isWordByte(sql[end]) {
+				end++
+			}
+
+			word := strings.ToUpper(sql[i:end])
+			switch {
+			case word == "SELECT" && start < 0:
+				start = end
+			case word == "FROM" && start >= 0:
+				return append(items, sql[start:i])
+			}
+
+			i = end - 1
+		}
+	}
+
+	if start >= 0 {
+		items = append(items, sql[start:])
+	}
+
+	return items
+}
+
+// normalizeSQL returns the shape of the statement: string and numeric literals
+// are replaced with "?", lists of them are collapsed to a single "?", comments
+// are removed and whitespace is collapsed.
+func normalizeSQL(sql string) string {
+	out := make([]byte, 0, len(sql))
+	space := false
+	write := func(s string) {
+		if space && len(out) > 0 && out[len(out)-1] != '(' && out[len(out)-1] != ',' && s[0] != ')' && s[0] != ',' {
+			out = append(out, ' ')
+		}
+
+		space = false
+		out = append(out, s...)
+	}
+
+	placeholder := func() {
+		if len(out) > 0 && out[len(out)-1] == ',' && len(out) > 1 && out[len(out)-2] == '?' {
+			out = out[:len(out)-1]
+			space = false
+			return
+		}
+
+		write("?")
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			space = true
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+
+			i += end
+			space = true
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i - 2
+			}
+
+			i += end + 3
+			space = true
+		case c == '\'':
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i++
+						continue
+					}
+
+					break
+				}
+			}
+
+			placeholder()
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+
+			end := strings.IndexByte(sql[i+1:], closing)
+			if end < 0 {
+				end = len(sql) - i - 1
+			}
+
+			write(sql[i:min(i+end+2, len(sql))])
+			i += end + 1
+		case c >= '0' && c <= '9' && (len(out) == 0 || space || !isWordByte(out[len(out)-1])):
+			for i+1 < len(sql) && (isWordByte(sql[i+1]) || sql[i+1] == '.') {
+				i++
+			}
+
+			placeholder()
+		case c == ')' && bytes.HasSuffix(out, []byte("(?),(?")):
+			out = out[:len(out)-3]
+			space = false
+		default:
+			write(sql[i : i+1])
+		}
+	}
+
+	return string(out)
+}
+
+// fingerprint returns a hash of the normalized statement, which is the same
+// for statements of the same shape.
+func fingerprint(sql string) uint64 {
+	return normalizedFingerprint(normalizeSQL(sql))
+}
+
+func normalizedFingerprint(normalized string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(normalized))
+	return h.Sum64()
+}
@@ -0,0 +1,146 @@
+package gormzerolog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDDL(t *testing.T) {
+	for sql, expected := range map[string]bool{
+		"CREATE TABLE `users` (`id` integer)":                          true,
+		"  create index idx_name ON users(name)":                       true,
+		"ALTER TABLE users ADD COLUMN age integer":                     true,
+		"DROP TABLE IF EXISTS users":                                   true,
+		"TRUNCATE users":                                               true,
+		"/* migration */ DROP INDEX idx_name":                          true,
+		"-- comment\nCREATE TABLE t (id int)":                          true,
+		"PRAGMA foreign_keys":                                          true,
+		"SELECT count(*) FROM sqlite_master WHERE type='table'":        true,
+		"SELECT * FROM information_schema.columns WHERE table_name=$1": true,
+		"SELECT CURRENT_DATABASE()":                                    true,
+		"SELECT * FROM users":                                          false,
+		"SELECT * FROM posts WHERE body LIKE '%sqlite_master%'":        false,
+		"SELECT * FROM docs WHERE title = 'information_schema.tables'": false,
+		"SELECT 1 /* pg_catalog. */":                                   false,
+		"INSERT INTO users (name) VALUES ('create')":                   false,
+		"UPDATE users SET name='drop'":                                 false,
+		"DELETE FROM users":                                            false,
+		"":                                                             false,
+	} {
+		assert.Equal(t, expected, isDDL(sql), sql)
+	}
+}
+
+func TestStatementKind(t *testing.T) {
+	for _, tc := range []struct {
+		sql   string
+		read  bool
+		write bool
+	}{
+		{"SELECT * FROM users", true, false},
+		{"(SELECT 1) UNION (SELECT 2)", true, false},
+		{"/* app */ select * from users", true, false},
+		{"WITH t AS (SELECT id FROM users) SELECT * FROM t", true, false},
+		{"WITH t AS (SELECT id FROM users) DELETE FROM sessions WHERE user_id IN (SELECT id FROM t)", false, true},
+		{"WITH \"select\" AS (SELECT 1) UPDATE users SET name = 'select'", false, true},
+		{"INSERT INTO users (name) VALUES ('select')", false, true},
+		{"UPDATE users SET name='a'", false, true},
+		{"DELETE FROM users", false, true},
+		{"REPLACE INTO users (id) VALUES (1)", false, true},
+		{"SELECT * FROM sqlite_master", false, false},
+		{"CREATE TABLE t (id int)", false, false},
+		{"CALL refresh_users()", false, false},
+		{"VACUUM", false, false},
+		{"", false, false},
+	} {
+		assert.Equal(t, tc.read, isRead(tc.sql), tc.sql)
+		assert.Equal(t, tc.write, isWrite(tc.sql), tc.sql)
+	}
+}
+
+func TestSQLOperationAndTable(t *testing.T) {
+	for _, tc := range []struct {
+		sql       string
+		operation string
+		table     string
+	}{
+		{"SELECT * FROM `users` WHERE `users`.`id` = 1", "select", "users"},
+		{"SELECT count(*) FROM (SELECT * FROM a) AS t", "select", ""},
+		{`SELECT * FROM "public"."orders" WHERE note = 'from x'`, "select", "public.orders"},
+		{"WITH t AS (SELECT * FROM a) SELECT * FROM t", "select", "t"},
+		{"INSERT INTO `users` (`name`) VALUES ('a')", "insert", "users"},
+		{"UPDATE users SET name = 'from'", "update", "users"},
+		{"DELETE FROM [sessions] WHERE id = 1", "delete", "sessions"},
+		{"CREATE TABLE t (id int)", "ddl", ""},
+		{"CALL refresh_users()", "call", ""},
+		{"", "", ""},
+	} {
+		assert.Equal(t, tc.operation, sqlOperation(tc.sql), tc.sql)
+		assert.Equal(t, tc.table, sqlTable(tc.sql), tc.sql)
+	}
+}
+
+func TestMaxInListSize(t *testing.T) {
+	for _, tc := range []struct {
+		sql  string
+		size int
+	}{
+		{"SELECT * FROM users WHERE id IN (1,2,3)", 3},
+		{"SELECT * FROM users WHERE id IN (?,?,?,?)", 4},
+		{"SELECT * FROM users WHERE id in ($1, $2)", 2},
+		{"SELECT * FROM users WHERE name IN ('a,b', 'c''d,e', \"f\")", 3},
+		{"SELECT * FROM users WHERE id IN (coalesce(1, 2), lower('A,B'))", 2},
+		{"SELECT * FROM users WHERE (a, b) IN ((1, 2), (3, 4), (5, 6))", 3},
+		{"SELECT * FROM users WHERE id IN (1) OR age IN (1, 2, 3, 4, 5)", 5},
+		{"SELECT * FROM users WHERE id IN (SELECT user_id FROM orders WHERE total IN (1, 2))", 2},
+		{"SELECT * FROM users WHERE id IN ()", 0},
+		{"SELECT * FROM users WHERE note = 'IN (1,2,3)'", 0},
+		{"SELECT * FROM login WHERE id = 1", 0},
+		{"SELECT * FROM users WHERE id IN (1, 2", 2},
+	} {
+		assert.Equal(t, tc.size, maxInListSize(tc.sql), tc.sql)
+	}
+}
+
+func TestIsUnboundedSelect(t *testing.T) {
+	for sql, expected := range map[string]bool{
+		"SELECT * FROM events":                                                  true,
+		"SELECT * FROM events WHERE id = 1":                                     true,
+		"SELECT * FROM events LIMIT 10":                                         false,
+		"SELECT * FROM events ORDER BY id FETCH FIRST 10 ROWS ONLY":             false,
+		"SELECT TOP 10 * FROM events":                                           false,
+		"SELECT count(*) FROM events":                                           false,
+		"SELECT COUNT(*) AS n, max(id) FROM events":                             false,
+		"SELECT count(*) FROM events GROUP BY kind":                             true,
+		"SELECT kind, count(*) FROM events":                                     true,
+		"SELECT counter FROM events":                                            true,
+		"SELECT * FROM events WHERE id IN (SELECT event_id FROM marks LIMIT 5)": true,
+		"SELECT * FROM events WHERE id IN (SELECT event_id FROM marks) LIMIT 5": false,
+		"SELECT * FROM events WHERE note = 'LIMIT 5'":                           true,
+		"SELECT (SELECT count(*) FROM marks) FROM events":                       true,
+		"WITH e AS (SELECT * FROM events LIMIT 5) SELECT * FROM e":              true,
+		"DELETE FROM events":                                                    false,
+	} {
+		assert.Equal(t, expected, isUnboundedSelect(sql), sql)
+	}
+}
+
+func TestNormalizeSQL(t *testing.T) {
+	for sql, expected := range map[string]string{
+		"SELECT * FROM users WHERE id = 1":                                           "SELECT * FROM users WHERE id = ?",
+		"SELECT * FROM users WHERE name = 'O''Brien' AND age > 3.5":                  "SELECT * FROM users WHERE name = ? AND age > ?",
+		"SELECT * FROM users WHERE id IN (1, 2, 3)":                                  "SELECT * FROM users WHERE id IN (?)",
+		"SELECT * FROM users WHERE id IN (1)":                                        "SELECT * FROM users WHERE id IN (?)",
+		"INSERT INTO `users` (`name`,`age`) VALUES ('a',1),('b',2), ('c', 3)":        "INSERT INTO `users` (`name`,`age`) VALUES (?)",
+		"SELECT  *\n\tFROM users -- comment\nWHERE id=1 /* hint */ LIMIT 10":         "SELECT * FROM users WHERE id=? LIMIT ?",
+		`SELECT "users"."id1" FROM "users" WHERE "users"."deleted_at" IS NULL`:       `SELECT "users"."id1" FROM "users" WHERE "users"."deleted_at" IS NULL`,
+		"SELECT * FROM t1 WHERE col2 = 'x'":                                          "SELECT * FROM t1 WHERE col2 = ?",
+		"SELECT * FROM users WHERE note = 'it''s (1,2)' OR id IN (SELECT id FROM t)": "SELECT * FROM users WHERE note = ? OR id IN (SELECT id FROM t)",
+	} {
+		assert.Equal(t, expected, normalizeSQL(sql), sql)
+	}
+
+	assert.Equal(t, fingerprint("SELECT * FROM users WHERE id IN (1,2)"), fingerprint("SELECT * FROM users WHERE id IN (3, 4, 5)"))
+	assert.NotEqual(t, fingerprint("SELECT * FROM users WHERE id = 1"), fingerprint("SELECT * FROM orders WHERE id = 1"))
+}
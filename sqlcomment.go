@@ -0,0 +1,143 @@
+package gormzerolog
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sqlCommentClause is the name of the clause appended to built statements.
+const sqlCommentClause = "GORMZEROLOG:SQLCOMMENT"
+
+// SQLComments configures sqlcommenter comments like /*app='svc',traceparent='...'*/
+// appended to statements before execution, so the database side, e.g.
+// pg_stat_activity or a slow query log, shows where a statement comes from.
+// Comments are built from the configured values only, never from statement
+// parameters.
+type SQLComments struct {
+	// Static are values added to every comment, e.g. app or framework.
+	Static map[string]string
+	// Context are functions returning values from the statement context,
+	// e.g. traceparent or route. Empty values are skipped.
+	Context map[string]func(ctx context.Context) string
+	// Strip removes the comment from the SQL logged by the logger.
+	// By default the SQL is logged as it is sent to the database.
+	Strip bool
+}
+
+// CommentSQL enables sqlcommenter comments appended to statements by
+// the Plugin. Statements are not modified if prepared statements are used,
+// as comments with varying values defeat the statement cache, in DryRun
+// sessions, if a raw statement already has a comment and if a clause builds
+// the statement by itself, e.g. soft delete of gorm.DeletedAt models.
+func (l *GormLogger) CommentSQL(c SQLComments) {
+	l.sqlComments = &c
+}
+
+// render returns the comment for the context, or an empty string if there
+// are no values.
+func (c *SQLComments) render(ctx context.Context) string {
+	values := make(map[string]string, len(c.Static)+len(c.Context))
+	for k, v := range c.Static {
+		if v != "" {
+			values[k] = v
+		}
+	}
+
+	if ctx != nil {
+		for k, f := range c.Context {
+			if v := f(ctx); v != "" {
+				values[k] = v
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString("/*")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		b.WriteString(sqlCommentEscape(k))
+		b.WriteString("='")
+		b.WriteString(sqlCommentEscape(values[k]))
+		b.WriteByte('\'')
+	}
+
+	b.WriteString("*/")
+	return b.String()
+}
+
+// sqlCommentEscape URL-encodes the key or value, so it can't contain quotes,
+// the end of the comment or placeholders.
+func sqlCommentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// usesPreparedStatements reports whether the statement is executed with
+// gorm prepared statement cache.
+func usesPreparedStatements(db *gorm.DB) bool {
+	switch db.Statement.ConnPool.(type) {
+	case *gorm.PreparedStmtDB, *gorm.PreparedStmtTX:
+		return true
+	}
+
+	return db.PrepareStmt
+}
+
+// commentStatement appends the sqlcommenter comment to the statement. Raw
+// statements are already built, so the comment is written to them directly,
+// otherwise it is added as the last clause to build.
+func (l *GormLogger) commentStatement(db *gorm.DB) {
+	c := l.sqlComments
+	if c == nil || db.Error != nil || db.DryRun || usesPreparedStatements(db) {
+		return
+	}
+
+	stmt := db.Statement
+	if stmt.SQL.Len() > 0 && (strings.Contains(stmt.SQL.String(), "/*") || strings.Contains(stmt.SQL.String(), "--")) {
+		return
+	}
+
+	comment := c.render(stmt.Context)
+	if comment == "" {
+		return
+	}
+
+	if info := statementInfoOf(db); info != nil && c.Strip {
+		info.sqlComment = comment
+	}
+
+	if stmt.SQL.Len() > 0 {
+		sql := stmt.SQL.String()
+		body := strings.TrimRight(sql, " \t\r\n;")
+		stmt.SQL.Reset()
+		stmt.SQL.WriteString(body)
+		stmt.SQL.WriteByte(' ')
+		stmt.SQL.WriteString(comment)
+		stmt.SQL.WriteString(sql[len(body):])
+		return
+	}
+
+	stmt.Clauses[sqlCommentClause] = clause.Clause{Expression: clause.Expr{SQL: comment}}
+	if !slices.Contains(stmt.BuildClauses, sqlCommentClause) {
+		// BuildClauses may be shared with the processor, so it is never appended in place
+		stmt.BuildClauses = append(slices.Clip(stmt.BuildClauses), sqlCommentClause)
+	}
+}
@@ -0,0 +1,27 @@
+package gormzerolog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingContextKey struct{}
+
+func TestSQLCommentsRender(t *testing.T) {
+	c := &SQLComments{
+		Static: map[string]string{"app": "my svc", "framework": "gorm", "empty": ""},
+		Context: map[string]func(context.Context) string{
+			"traceparent": func(ctx context.Context) string {
+				v, _ := ctx.Value(testingContextKey{}).(string)
+				return v
+			},
+		},
+	}
+
+	assert.Equal(t, "/*app='my%20svc',framework='gorm'*/", c.render(context.Background()))
+	ctx := context.WithValue(context.Background(), testingContextKey{}, "00-4bf9-00f0-01?'*/")
+	assert.Equal(t, "/*app='my%20svc',framework='gorm',traceparent='00-4bf9-00f0-01%3F%27%2A%2F'*/", c.render(ctx))
+	assert.Empty(t, (&SQLComments{}).render(ctx))
+}
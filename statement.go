@@ -0,0 +1,202 @@
+package gormzerolog
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type statementInfoKey struct{}
+
+// statementInfo is metadata of a statement stashed into its context by
+// the plugin callbacks.
+type statementInfo struct {
+	stmt      *gorm.Statement
+	table     string
+	model     string
+	operation string
+	dryRun    bool
+	inTx      bool
+
+	pks          []any
+	pksTruncated bool
+
+	// paramCount is the number of bind parameters seen by ParamsFilter, -1 if unknown.
+	paramCount int
+
+	// sqlComment is the sqlcommenter comment to strip from the logged SQL.
+	sqlComment string
+
+	// dbStart and dbTime measure the query callback of the statement.
+	dbStart time.Time
+	dbTime  time.Duration
+
+	// id is the statement ID, set if statements are tracked.
+	id string
+	// pool is the connection pool replaced by wrapConnPool.
+	pool gorm.ConnPool
+	// watch is the watchdog armed by armWatchdog.
+	watch *inFlight
+}
+
+func statementInfoFromContext(ctx context.Context) *statementInfo {
+	if ctx == nil {
+		return nil
+	}
+
+	info, _ := ctx.Value(statementInfoKey{}).(*statementInfo)
+	return info
+}
+
+// statementCallback returns a callback stashing metadata of the statement
+// into its context. Operation is left empty for raw statements, so it is
+// determined from the SQL.
+func statementCallback(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		info := statementInfoFromContext(stmt.Context)
+		// a statement reused by chained calls keeps its info, statements
+		// of associations get their own
+		if info == nil || info.stmt != stmt {
+			ctx := stmt.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			info = &statementInfo{stmt: stmt}
+			stmt.Context = context.WithValue(ctx, statementInfoKey{}, info)
+		}
+
+		info.table = stmt.Table
+		info.model = ""
+		if stmt.Schema != nil {
+			info.model = stmt.Schema.Name
+		}
+
+		info.operation = operation
+		info.dryRun = stmt.DB != nil && stmt.DB.DryRun
+		// the implicit transaction of a single write is begun after this callback
+		_, info.inTx = stmt.ConnPool.(gorm.TxCommitter)
+		info.dbStart = time.Time{}
+		info.dbTime = 0
+		info.pks = nil
+		info.pksTruncated = false
+		info.paramCount = -1
+		info.sqlComment = ""
+		info.id = ""
+		disarmWatchdog(info)
+	}
+}
+
+// statementInfoOf returns the info stashed by statementCallback for the statement.
+func statementInfoOf(db *gorm.DB) *statementInfo {
+	info := statementInfoFromContext(db.Statement.Context)
+	if info == nil || info.stmt != db.Statement {
+		return nil
+	}
+
+	return info
+}
+
+type registerFunc func(name string, fn func(*gorm.DB)) error
+
+// commitCallback ends the implicit transaction of writes, which expects
+// the connection pool restored by unwrapConnPool.
+const commitCallback = "gorm:commit_or_rollback_transaction"
+
+// registerStatementCallbacks registers callbacks stashing statement
+// metadata before any other callback of each processor, callbacks appending
+// sqlcommenter comments and measuring the time spent in the query callback
+// before it, and callbacks collecting primary keys after it.
+func (l *GormLogger) registerStatementCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, c := range []struct {
+		name, operation      string
+		first, before, after registerFunc
+	}{
+		{"create", "insert", cb.Create().Before("*").Register, cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Before(commitCallback).Register},
+		{"query", "select", cb.Query().Before("*").Register, cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Before(commitCallback).Register},
+		{"update", "update", cb.Update().Before("*").Register, cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Before(commitCallback).Register},
+		{"delete", "delete", cb.Delete().Before("*").Register, cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Before(commitCallback).Register},
+		{"row", "", cb.Row().Before("*").Register, cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Before(commitCallback).Register},
+		{"raw", "", cb.Raw().Before("*").Register, cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Before(commitCallback).Register},
+	} {
+		if err := c.first(pluginName+":statement_"+c.name, statementCallback(c.operation)); err != nil {
+			return err
+		}
+
+		if err := c.before(pluginName+":db_start_"+c.name, func(db *gorm.DB) {
+			l.commentStatement(db)
+			if info := statementInfoOf(db); info != nil {
+				info.dbStart = l.clock.Now()
+				if l.tracksStatements() {
+					info.id = newStatementID()
+					l.wrapConnPool(db, info)
+					l.armWatchdog(db.Statement.Context, info)
+				}
+			}
+		}); err != nil {
+			return err
+		}
+
+		audit := c.name == "create" || c.name == "update" || c.name == "delete"
+		if err := c.after(pluginName+":db_end_"+c.name, func(db *gorm.DB) {
+			info := statementInfoOf(db)
+			if info == nil {
+				return
+			}
+
+			unwrapConnPool(db, info)
+			disarmWatchdog(info)
+			if !info.dbStart.IsZero() {
+				info.dbTime = l.clock.Now().Sub(info.dbStart)
+			}
+
+			if audit && l.auditPKLimit > 0 {
+				info.pks, info.pksTruncated = primaryKeys(db.Statement, l.auditPKLimit)
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LogPhaseTimesOnAll sets a flag for logging db_time and callbacks_time fields
+// on every trace. By default only errors and slow queries carry them.
+// The times are measured only if the logger is attached with the Plugin.
+func (l *GormLogger) LogPhaseTimesOnAll(b bool) {
+	l.phaseTimesOnAll = b
+}
+
+// DryRunLevel sets a log level for traces of statements generated by DryRun
+// sessions, which are logged at Info by default. The traces carry
+// the dry_run field if the logger is attached with the Plugin.
+func (l *GormLogger) DryRunLevel(logLevel logger.LogLevel) {
+	l.dryRunLevel = logLevel
+}
+
+// ParameterizedQueries sets a flag for logging statements with placeholders
+// instead of parameter values, which keeps user data out of logs.
+func (l *GormLogger) ParameterizedQueries(b bool) {
+	l.parameterizedQueries = b
+}
+
+// ParamsFilter implements gorm.ParamsFilter. It keeps the statement and its
+// parameters intact, unless ParameterizedQueries is set, and counts the
+// parameters for the param_count field, which is logged only if the logger
+// is attached with the Plugin.
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	if info := statementInfoFromContext(ctx); info != nil {
+		info.paramCount = len(params)
+	}
+
+	if l.parameterizedQueries {
+		return sql, nil
+	}
+
+	return sql, params
+}
@@ -0,0 +1,60 @@
+package gormzerolog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var _ gorm.ParamsFilter = (*GormLogger)(nil)
+
+func TestGormLoggerParamCount(t *testing.T) {
+	for _, tc := range []struct {
+		sql    string
+		params []any
+	}{
+		{"SELECT * FROM users WHERE id IN (?,?,?)", []any{1, 2, 3}},
+		{"SELECT * FROM users WHERE name = $1 OR nick = $1 AND age > $2", []any{"a", 18}},
+		{"SELECT * FROM users", nil},
+	} {
+		t.Run(tc.sql, func(t *testing.T) {
+			infoSink := &testingSink{}
+			l := NewGormLogger().WithInfo(infoSink.factory)
+			ctx := context.WithValue(context.Background(), statementInfoKey{}, &statementInfo{paramCount: -1})
+			l.Trace(ctx, time.Now(), func() (string, int64) {
+				sql, params := l.ParamsFilter(ctx, tc.sql, tc.params...)
+				assert.Equal(t, tc.sql, sql)
+				assert.Equal(t, tc.params, params)
+				return sql, 1
+			}, nil)
+			require.Len(t, infoSink.events, 1)
+			assert.Equal(t, len(tc.params), infoSink.events[0].fields["param_count"])
+		})
+	}
+
+	t.Run("without plugin", func(t *testing.T) {
+		infoSink := &testingSink{}
+		l := NewGormLogger().WithInfo(infoSink.factory)
+		l.Trace(context.Background(), time.Now(), func() (string, int64) {
+			sql, _ := l.ParamsFilter(context.Background(), "SELECT ?", 1)
+			return sql, 1
+		}, nil)
+		require.Len(t, infoSink.events, 1)
+		assert.NotContains(t, infoSink.events[0].fields, "param_count")
+	})
+}
+
+func TestGormLoggerParameterizedQueries(t *testing.T) {
+	l := NewGormLogger()
+	l.ParameterizedQueries(true)
+	info := &statementInfo{paramCount: -1}
+	ctx := context.WithValue(context.Background(), statementInfoKey{}, info)
+	sql, params := l.ParamsFilter(ctx, "SELECT * FROM users WHERE id = ?", 1)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ?", sql)
+	assert.Nil(t, params)
+	assert.Equal(t, 1, info.paramCount)
+}
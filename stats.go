@@ -0,0 +1,40 @@
+package gormzerolog
+
+import (
+	"sync/atomic"
+)
+
+// Stats is a snapshot of the logger counters.
+type Stats struct {
+	// Traces is a number of traced statements.
+	Traces uint64
+	// Overloaded reports whether successful trace logging is suspended
+	// by the overload protection.
+	Overloaded bool
+	// OverloadTransitions is a number of overload protection state changes.
+	OverloadTransitions uint64
+	// CollapsedLabels is a number of metric label values replaced with
+	// OtherLabel by LimitMetricLabels.
+	CollapsedLabels uint64
+	// DroppedEvents is a number of trace records dropped by the backpressure
+	// policy of the asynchronous emission.
+	DroppedEvents uint64
+}
+
+type loggerStats struct {
+	traces              atomic.Uint64
+	overloadTransitions atomic.Uint64
+	collapsedLabels     atomic.Uint64
+	droppedEvents       atomic.Uint64
+}
+
+// Stats returns a snapshot of the logger counters.
+func (l *GormLogger) Stats() Stats {
+	return Stats{
+		Traces:              l.stats.traces.Load(),
+		Overloaded:          l.overload != nil && l.overload.tripped.Load(),
+		OverloadTransitions: l.stats.overloadTransitions.Load(),
+		CollapsedLabels:     l.stats.collapsedLabels.Load(),
+		DroppedEvents:       l.stats.droppedEvents.Load(),
+	}
+}
@@ -0,0 +1,85 @@
+package gormzerolog
+
+import (
+	"slices"
+	"sync"
+)
+
+// TestingTB is the part of testing.TB used by WithTestingTB.
+type TestingTB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// strictMode reports unexpected errors and slow queries to a test.
+type strictMode struct {
+	tb   TestingTB
+	slow bool
+
+	mu      sync.RWMutex
+	allowed []TraceMatcher
+}
+
+// WithTestingTB returns a copy of the logger making every logged statement
+// error fail the test with tb.Errorf, reporting the error, the SQL and the
+// caller. Errors ignored by IgnoreRecordNotFoundError, records dropped by
+// interceptors and errors allowed with AllowErrors don't fail the test. It is
+// safe to use with parallel tests sharing the logger. Nil tb disables the
+// strict mode.
+func (l *GormLogger) WithTestingTB(tb TestingTB) *GormLogger {
+	c := l.clone()
+	c.strict = nil
+	if tb != nil {
+		c.strict = &strictMode{tb: tb}
+	}
+
+	return c
+}
+
+// FailOnSlowQueries sets a flag for failing the test set with WithTestingTB
+// on slow queries too.
+func (l *GormLogger) FailOnSlowQueries(b bool) {
+	if l.strict != nil {
+		l.strict.slow = b
+	}
+}
+
+// AllowErrors registers expected errors of statements matched by any of the
+// matchers, which don't fail the test set with WithTestingTB.
+func (l *GormLogger) AllowErrors(matchers ...TraceMatcher) {
+	if l.strict == nil {
+		return
+	}
+
+	l.strict.mu.Lock()
+	defer l.strict.mu.Unlock()
+	l.strict.allowed = append(slices.Clip(l.strict.allowed), matchers...)
+}
+
+func (s *strictMode) allows(rec *TraceRecord) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, m := range s.allowed {
+		if m(rec) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// failOnStrict reports the record to the test if it is an unexpected error
+// or a slow query.
+func (l *GormLogger) failOnStrict(rec *TraceRecord) {
+	s := l.strict
+	switch {
+	case l.isLoggedError(rec):
+		if !s.allows(rec) {
+			s.tb.Helper()
+			s.tb.Errorf("unexpected SQL error: %v\nSQL: %s\ncaller: %s", rec.Err, rec.SQL, rec.Caller)
+		}
+	case s.slow && l.isSlow(rec):
+		s.tb.Helper()
+		s.tb.Errorf("slow SQL: %s >= %s\nSQL: %s\ncaller: %s", rec.Elapsed, rec.threshold, rec.SQL, rec.Caller)
+	}
+}
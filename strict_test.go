@@ -0,0 +1,113 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+type fakeTB struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...any) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestGormLoggerWithTestingTB(t *testing.T) {
+	assert := assert.New(t)
+	tb := &fakeTB{}
+	nop := func() Event { return &testingEvent{} }
+	l := NewGormLogger().
+		WithInfo(nop).WithWarn(nop).WithError(nop).
+		WithCallerResolver(func(context.Context) string { return "app/users.go:42" }).
+		WithTestingTB(tb)
+	l.IgnoreRecordNotFoundError(true)
+	trace := func(sql string, elapsed time.Duration, err error) {
+		l.Trace(context.Background(), time.Now().Add(-elapsed), func() (string, int64) { return sql, 0 }, err)
+	}
+
+	trace("SELECT * FROM users", 0, nil)
+	trace("SELECT * FROM users WHERE id = 1", 0, logger.ErrRecordNotFound)
+	trace("SELECT * FROM orders", time.Second, nil)
+	assert.Empty(tb.errors)
+
+	trace("INSERT INTO users (email) VALUES ('a')", 0, errors.New("UNIQUE constraint failed: users.email"))
+	require.Len(t, tb.errors, 1)
+	assert.Equal("unexpected SQL error: UNIQUE constraint failed: users.email\n"+
+		"SQL: INSERT INTO users (email) VALUES ('a')\ncaller: app/users.go:42", tb.errors[0])
+
+	t.Run("allowed errors", func(t *testing.T) {
+		tb.errors = nil
+		l.AllowErrors(
+			MatchSQL(regexp.MustCompile(`^INSERT INTO users`)),
+			func(rec *TraceRecord) bool { return errors.Is(rec.Err, context.Canceled) },
+		)
+		trace("INSERT INTO users (email) VALUES ('a')", 0, errors.New("UNIQUE constraint failed: users.email"))
+		trace("SELECT * FROM orders", 0, context.Canceled)
+		assert.Empty(tb.errors)
+
+		trace("DELETE FROM users", 0, errors.New("locked"))
+		assert.Len(tb.errors, 1)
+	})
+
+	t.Run("dropped by interceptors", func(t *testing.T) {
+		tb.errors = nil
+		l := l.clone()
+		l.Use(func(_ context.Context, rec *TraceRecord) bool { return rec.Table != "jobs" })
+		trace := func(sql string) {
+			l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 0 }, errors.New("locked"))
+		}
+
+		trace("DELETE FROM jobs")
+		assert.Empty(tb.errors)
+		trace("DELETE FROM sessions")
+		assert.Len(tb.errors, 1)
+	})
+
+	t.Run("slow queries", func(t *testing.T) {
+		tb.errors = nil
+		l.FailOnSlowQueries(true)
+		defer l.FailOnSlowQueries(false)
+		trace("SELECT * FROM orders", time.Millisecond, nil)
+		trace("SELECT * FROM orders", time.Second, nil)
+		require.Len(t, tb.errors, 1)
+		assert.Regexp(`^slow SQL: 1\.0\d*s >= 200ms\nSQL: SELECT \* FROM orders\ncaller: app/users.go:42$`, tb.errors[0])
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		tb.errors = nil
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				l.AllowErrors(MatchTables("parallel"))
+				trace("DELETE FROM sessions", 0, errors.New("locked"))
+			}()
+		}
+
+		wg.Wait()
+		assert.Len(tb.errors, 8)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tb.errors = nil
+		l = l.WithTestingTB(nil)
+		trace("DELETE FROM sessions", 0, errors.New("locked"))
+		assert.Empty(tb.errors)
+	})
+}
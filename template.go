@@ -0,0 +1,330 @@
+package gormzerolog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm/logger"
+)
+
+// Template tokens replaced with trace values when a message is rendered.
+const (
+	// TokenCaller is the file and line of the caller.
+	TokenCaller = "{caller}"
+	// TokenError is the error text, allowed in the Error template only.
+	TokenError = "{error}"
+	// TokenSlow is the slow query notice, allowed in the Slow template only.
+	TokenSlow = "{slow}"
+	// TokenElapsed is the elapsed time in milliseconds with three decimals.
+	TokenElapsed = "{elapsed}"
+	// TokenRows is the number of rows, or Literals.UnknownRows if it is unknown.
+	// The number is logged in the rows field too.
+	TokenRows = "{rows}"
+	// TokenSQL is the SQL statement.
+	TokenSQL = "{sql}"
+)
+
+// Placement defines where the SQL statement of a trace is logged.
+type Placement int
+
+const (
+	// OwnLine appends the SQL to the message on its own line, which is
+	// readable with zerolog.ConsoleWriter.
+	OwnLine Placement = iota
+	// Inline logs the SQL in the sql field and keeps the message on
+	// a single line, which suits JSON output.
+	Inline
+	// Structured logs the SQL and the elapsed time in the sql and elapsed_ms
+	// fields, leaving only the caller and the error or slow query notice in
+	// the message, so JSON consumers don't have to parse it.
+	Structured
+)
+
+// String returns the name of the placement.
+func (p Placement) String() string {
+	switch p {
+	case OwnLine:
+		return "own_line"
+	case Inline:
+		return "inline"
+	case Structured:
+		return "structured"
+	default:
+		return "unknown"
+	}
+}
+
+// TraceTemplates are templates of trace messages. A template is a text with
+// tokens like {caller} and {sql}; literal braces are written as {{ and }}.
+// If the caller is empty, a caller token at the beginning of a template is
+// removed together with a single space or newline following it. If the SQL
+// is omitted with SQLOnLevels, a SQL token is removed together with a single
+// space or newline preceding it.
+// Empty templates keep their current values, initially the defaults
+// of the SQL placement.
+type TraceTemplates struct {
+	// Error is a template of failed query messages.
+	Error string
+	// Slow is a template of slow query messages.
+	Slow string
+	// Info is a template of other query messages.
+	Info string
+}
+
+var (
+	defaultTemplates = map[Placement]traceTemplates{
+		OwnLine: mustParseTraceTemplates(TraceTemplates{
+			Error: "{caller} {error}\n[{elapsed}ms] [rows:{rows}] {sql}",
+			Slow:  "{caller} {slow}\n[{elapsed}ms] [rows:{rows}] {sql}",
+			Info:  "{caller}\n[{elapsed}ms] [rows:{rows}] {sql}",
+		}),
+		Inline: mustParseTraceTemplates(TraceTemplates{
+			Error: "{caller} {error} [{elapsed}ms] [rows:{rows}]",
+			Slow:  "{caller} {slow} [{elapsed}ms] [rows:{rows}]",
+			Info:  "{caller} [{elapsed}ms] [rows:{rows}]",
+		}),
+		Structured: mustParseTraceTemplates(TraceTemplates{
+			Error: "{caller} {error}",
+			Slow:  "{caller} {slow}",
+			Info:  "{caller}",
+		}),
+	}
+)
+
+type traceTemplates struct {
+	err  traceTemplate
+	slow traceTemplate
+	info traceTemplate
+}
+
+func mustParseTraceTemplates(t TraceTemplates) traceTemplates {
+	var c traceTemplates
+	if err := c.set(t); err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// set parses non-empty templates, leaving the current ones untouched on error.
+func (c *traceTemplates) set(t TraceTemplates) error {
+	parsed := *c
+	for _, p := range []struct {
+		name    string
+		text    string
+		dst     *traceTemplate
+		allowed string
+	}{
+		{"error", t.Error, &parsed.err, TokenError},
+		{"slow", t.Slow, &parsed.slow, TokenSlow},
+		{"info", t.Info, &parsed.info, ""},
+	} {
+		if p.text == "" {
+			continue
+		}
+
+		tmpl, err := parseTraceTemplate(p.text, p.allowed)
+		if err != nil {
+			return fmt.Errorf("invalid %s template %q: %w", p.name, p.text, err)
+		}
+
+		*p.dst = tmpl
+	}
+
+	*c = parsed
+	return nil
+}
+
+// pick returns the template if it is set, otherwise the default one.
+func (t traceTemplate) pick(def traceTemplate) traceTemplate {
+	if t == nil {
+		return def
+	}
+
+	return t
+}
+
+// SetTraceTemplates sets templates of trace messages. An error is returned
+// if a template is malformed or uses a token not allowed in it.
+func (l *GormLogger) SetTraceTemplates(t TraceTemplates) error {
+	return l.templates.set(t)
+}
+
+// SQLPlacement sets where the SQL statement of a trace is logged.
+// Default is OwnLine.
+func (l *GormLogger) SQLPlacement(p Placement) {
+	l.sqlPlacement = p
+}
+
+// SQLOnLevels limits logging of the SQL statement to trace events of the given
+// levels, e.g. logger.Error and logger.Warn to keep routine traces metadata
+// only. Slow query traces are logged at Warn. Without levels the SQL is logged
+// on all levels, which is the default.
+func (l *GormLogger) SQLOnLevels(levels ...logger.LogLevel) {
+	if len(levels) == 0 {
+		l.sqlLevels = nil
+		return
+	}
+
+	l.sqlLevels = make(map[logger.LogLevel]struct{}, len(levels))
+	for _, level := range levels {
+		l.sqlLevels[level] = struct{}{}
+	}
+}
+
+// includesSQL reports whether the SQL is logged in trace events of the level.
+func (l *GormLogger) includesSQL(logLevel logger.LogLevel) bool {
+	if l.sqlLevels == nil {
+		return true
+	}
+
+	_, ok := l.sqlLevels[logLevel]
+	return ok
+}
+
+// TruncateSQL limits the logged SQL to limit bytes, cutting it at a character
+// boundary and marking the event with the sql_truncated field. Records passed
+// to interceptors and hooks keep the full SQL. Zero disables the limit, which
+// is the default.
+func (l *GormLogger) TruncateSQL(limit int) {
+	l.sqlLimit = max(limit, 0)
+}
+
+// QueryFingerprints sets a flag for logging a hash of the normalized SQL,
+// with literal values stripped, in the query_fingerprint field of traces,
+// so statements of the same shape can be grouped.
+func (l *GormLogger) QueryFingerprints(b bool) {
+	l.queryFingerprints = b
+}
+
+// NormalizedSQL sets a flag for logging the SQL with literal values replaced
+// with placeholders in the sql_normalized field of traces, which keeps logs
+// useful for shape analysis without the values. It is truncated and limited
+// to levels like the SQL.
+func (l *GormLogger) NormalizedSQL(b bool) {
+	l.normalizedSQL = b
+}
+
+// truncateSQL cuts the SQL to limit bytes followed by an ellipsis.
+func truncateSQL(sql string, limit int) (string, bool) {
+	if limit <= 0 || len(sql) <= limit {
+		return sql, false
+	}
+
+	for limit > 0 && !utf8.RuneStart(sql[limit]) {
+		limit--
+	}
+
+	return sql[:limit] + "...", true
+}
+
+type templateSegment struct {
+	literal string
+	token   string
+}
+
+type traceTemplate []templateSegment
+
+type templateValues struct {
+	caller      string
+	message     string
+	elapsed     time.Duration
+	rows        int64
+	unknownRows string
+	sql         string
+	omitSQL     bool
+}
+
+func parseTraceTemplate(text, allowed string) (traceTemplate, error) {
+	var tmpl traceTemplate
+	var literal strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '{' && strings.HasPrefix(text[i:], "{{"), c == '}' && strings.HasPrefix(text[i:], "}}"):
+			literal.WriteByte(c)
+			i++
+		case c == '}':
+			return nil, fmt.Errorf("unexpected } at %d", i)
+		case c == '{':
+			end := strings.IndexByte(text[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed token at %d", i)
+			}
+
+			token := text[i : i+end+1]
+			switch token {
+			case TokenCaller, TokenElapsed, TokenRows, TokenSQL:
+			case TokenError, TokenSlow:
+				if token != allowed {
+					return nil, fmt.Errorf("token %s is not allowed", token)
+				}
+			default:
+				return nil, fmt.Errorf("unknown token %s", token)
+			}
+
+			if literal.Len() > 0 {
+				tmpl = append(tmpl, templateSegment{literal: literal.String()})
+				literal.Reset()
+			}
+
+			tmpl = append(tmpl, templateSegment{token: token})
+			i += end
+		default:
+			literal.WriteByte(c)
+		}
+	}
+
+	if literal.Len() > 0 {
+		tmpl = append(tmpl, templateSegment{literal: literal.String()})
+	}
+
+	return tmpl, nil
+}
+
+func (t traceTemplate) render(v templateValues) string {
+	buf := make([]byte, 0, 64+len(v.sql)+len(v.caller)+len(v.message))
+	segments := t
+	if v.caller == "" && len(segments) > 0 && segments[0].token == TokenCaller {
+		segments = segments[1:]
+		if len(segments) > 0 && segments[0].token == "" {
+			literal := segments[0].literal
+			if literal[0] == ' ' || literal[0] == '\n' {
+				literal = literal[1:]
+			}
+
+			buf = append(buf, literal...)
+			segments = segments[1:]
+		}
+	}
+
+	for _, s := range segments {
+		switch s.token {
+		case "":
+			buf = append(buf, s.literal...)
+		case TokenCaller:
+			buf = append(buf, v.caller...)
+		case TokenError, TokenSlow:
+			buf = append(buf, v.message...)
+		case TokenElapsed:
+			buf = strconv.AppendFloat(buf, float64(v.elapsed.Nanoseconds())/1e6, 'f', 3, 64)
+		case TokenRows:
+			if v.rows == -1 {
+				buf = append(buf, v.unknownRows...)
+			} else {
+				buf = strconv.AppendInt(buf, v.rows, 10)
+			}
+		case TokenSQL:
+			if !v.omitSQL {
+				buf = append(buf, v.sql...)
+			} else if n := len(buf); n > 0 && (buf[n-1] == ' ' || buf[n-1] == '\n') {
+				buf = buf[:n-1]
+			}
+		}
+	}
+
+	return string(buf)
+}
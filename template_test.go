@@ -0,0 +1,218 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLoggerTraceTemplates(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().
+		WithInfo(infoSink.factory).
+		WithWarn(warnSink.factory).
+		WithError(errorSink.factory).
+		WithClock(clock).
+		WithCallerResolver(func(context.Context) string { return "app.go:1" })
+	l.SlowThreshold(time.Millisecond * 100)
+	require.NoError(t, l.SetTraceTemplates(TraceTemplates{
+		Error: "{error} | {sql} | rows={rows} | {elapsed}ms | {caller}",
+		Slow:  "{slow}: {sql} took {elapsed}ms",
+		Info:  "{{{sql}}} rows={rows}",
+	}))
+
+	l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("test"))
+	l.Trace(context.Background(), clock.Now().Add(-time.Second), func() (string, int64) { return "SELECT 2", -1 }, nil)
+	assert.Equal([]string{"test | SELECT 1 | rows=1 | 0.000ms | app.go:1"}, errorSink.messages())
+	assert.Equal([]string{"SLOW SQL >= 100ms: SELECT 2 took 1000.000ms"}, warnSink.messages())
+	assert.Equal([]string{"{SELECT 1} rows=1", "{SELECT 2} rows=-"}, infoSink.messages())
+
+	require.NoError(t, l.SetTraceTemplates(TraceTemplates{Info: "{sql}"}))
+	l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "SELECT 3", 1 }, errors.New("test"))
+	assert.Equal("test | SELECT 3 | rows=1 | 0.000ms | app.go:1", errorSink.messages()[1])
+	assert.Equal("SELECT 3", infoSink.messages()[2])
+}
+
+func TestGormLoggerTraceTemplatesEmptyCaller(t *testing.T) {
+	infoSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithCallerResolver(func(context.Context) string { return "" })
+	require.NoError(t, l.SetTraceTemplates(TraceTemplates{Info: "{caller} {sql} {caller}"}))
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	assert.Equal(t, []string{"SELECT 1 "}, infoSink.messages())
+}
+
+func TestGormLoggerTraceTemplatesInvalid(t *testing.T) {
+	for name, templates := range map[string]TraceTemplates{
+		"unknown token":      {Error: "{error} {sql}", Info: "{query}"},
+		"unclosed token":     {Info: "{sql"},
+		"unexpected brace":   {Slow: "{slow} }"},
+		"error outside info": {Info: "{error}"},
+		"slow in error":      {Error: "{slow}"},
+		"error in slow":      {Slow: "{error}"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := NewGormLogger()
+			err := l.SetTraceTemplates(templates)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "template")
+			assert.Equal(t, traceTemplates{}, l.templates, "templates must be kept on error")
+		})
+	}
+}
+
+func TestGormLoggerSQLOnLevels(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().
+		WithInfo(infoSink.factory).
+		WithWarn(warnSink.factory).
+		WithError(errorSink.factory).
+		WithCallerResolver(func(context.Context) string { return "app.go:1" })
+	l.SlowThreshold(time.Millisecond * 100)
+	l.SQLOnLevels(logger.Error, logger.Warn)
+	trace := func() {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("test"))
+		l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 2", 1 }, nil)
+	}
+
+	trace()
+	assert.Contains(errorSink.messages()[0], "SELECT 1")
+	assert.Contains(warnSink.messages()[0], "SELECT 2")
+	require.Len(t, infoSink.messages(), 2)
+	for _, msg := range infoSink.messages() {
+		assert.NotContains(msg, "SELECT")
+		assert.Regexp(`^app\.go:1\n\[[0-9.]+ms\] \[rows:1\]$`, msg)
+	}
+
+	assert.Equal("select", infoSink.events[0].added["operation"])
+
+	l.SQLPlacement(Inline)
+	trace()
+	assert.Equal("SELECT 1", errorSink.events[1].added["sql"])
+	assert.Equal("SELECT 2", warnSink.events[1].added["sql"])
+	assert.NotContains(infoSink.events[2].added, "sql")
+
+	l.SQLOnLevels()
+	trace()
+	assert.Equal("SELECT 1", infoSink.events[4].added["sql"])
+}
+
+func TestGormLoggerStructuredPlacement(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	infoSink := &testingSink{}
+	warnSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().
+		WithInfo(infoSink.factory).
+		WithWarn(warnSink.factory).
+		WithError(errorSink.factory).
+		WithClock(clock).
+		WithCallerResolver(func(context.Context) string { return "app.go:1" })
+	l.SlowThreshold(time.Millisecond * 100)
+	l.SQLPlacement(Structured)
+	l.Trace(context.Background(), clock.Now().Add(-1500*time.Microsecond), func() (string, int64) { return "UPDATE users SET name = 'a'", 2 }, errors.New("test"))
+	l.Trace(context.Background(), clock.Now().Add(-time.Second), func() (string, int64) { return "SELECT 2", -1 }, nil)
+
+	assert.Equal([]string{"app.go:1 test"}, errorSink.messages())
+	assert.Equal([]string{"app.go:1 SLOW SQL >= 100ms"}, warnSink.messages())
+	assert.Equal([]string{"app.go:1", "app.go:1"}, infoSink.messages())
+	e := errorSink.events[0]
+	assert.Equal("UPDATE users SET name = 'a'", e.added["sql"])
+	assert.Equal(int64(2), e.fields["rows"])
+	assert.Equal(int64(2), e.fields["rows_affected"])
+	assert.Equal(1.5, e.fields["elapsed_ms"])
+	assert.NotContains(e.fields, "rows_returned")
+
+	e = warnSink.events[0]
+	assert.Equal("SELECT 2", e.added["sql"])
+	assert.NotContains(e.fields, "rows")
+	assert.Equal(1000.0, e.fields["elapsed_ms"])
+
+	l.SQLOnLevels(logger.Error)
+	l.Trace(context.Background(), clock.Now(), func() (string, int64) { return "SELECT 3", 1 }, nil)
+	assert.NotContains(infoSink.events[2].added, "sql")
+	assert.Equal("app.go:1", infoSink.messages()[2])
+}
+
+func TestGormLoggerTruncateSQL(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	l := NewGormLogger().
+		WithInfo(infoSink.factory).
+		WithCallerResolver(func(context.Context) string { return "" })
+	l.SQLPlacement(Inline)
+	l.TruncateSQL(11)
+	var traced string
+	l.Use(func(_ context.Context, rec *TraceRecord) bool {
+		traced = rec.SQL
+		return true
+	})
+
+	for _, sql := range []string{"SELECT 1", "SELECT 'привет'"} {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	require.Len(t, infoSink.events, 2)
+	assert.Equal("SELECT 1", infoSink.events[0].added["sql"])
+	assert.NotContains(infoSink.events[0].fields, "sql_truncated")
+	assert.Equal("SELECT 'п...", infoSink.events[1].added["sql"])
+	assert.Equal(true, infoSink.events[1].fields["sql_truncated"])
+	assert.Equal("SELECT 'привет'", traced)
+
+	l.SQLPlacement(OwnLine)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 'привет'", 1 }, nil)
+	assert.True(strings.HasSuffix(infoSink.messages()[2], "] SELECT 'п..."))
+}
+
+func TestGormLoggerQueryFingerprints(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory)
+	trace := func(sql string) string {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, nil)
+		return infoSink.events[len(infoSink.events)-1].added["query_fingerprint"]
+	}
+
+	assert.Empty(trace("SELECT * FROM users WHERE id = 1"))
+
+	l.QueryFingerprints(true)
+	first := trace("SELECT * FROM users WHERE id = 1")
+	assert.Regexp(`^[0-9a-f]{16}$`, first)
+	assert.Equal(first, trace("SELECT *  FROM users WHERE id = 42"))
+	assert.Equal(first, trace("SELECT * FROM users WHERE id = 1"))
+	assert.NotEqual(first, trace("SELECT * FROM users WHERE email = 'a'"))
+}
+
+func TestGormLoggerNormalizedSQL(t *testing.T) {
+	assert := assert.New(t)
+	infoSink := &testingSink{}
+	errorSink := &testingSink{}
+	l := NewGormLogger().WithInfo(infoSink.factory).WithError(errorSink.factory)
+	l.NormalizedSQL(true)
+	trace := func(sql string, err error) {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, err)
+	}
+
+	trace("SELECT * FROM users WHERE email = 'a@example.com' AND id IN (1, 2, 3)", nil)
+	require.Len(t, infoSink.events, 1)
+	assert.Equal("SELECT * FROM users WHERE email = ? AND id IN (?)", infoSink.events[0].added["sql_normalized"])
+
+	l.TruncateSQL(20)
+	l.SQLOnLevels(logger.Error)
+	trace("SELECT * FROM users WHERE id = 1", errors.New("test"))
+	assert.Equal("SELECT * FROM users ...", errorSink.events[0].added["sql_normalized"])
+	assert.NotContains(infoSink.events[1].added, "sql_normalized")
+}
@@ -0,0 +1,137 @@
+package gormzerolog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// tenantConfig resolves tenants of statements and holds their overrides.
+type tenantConfig struct {
+	resolve func(ctx context.Context) string
+
+	mu         sync.RWMutex
+	levels     map[string]logger.LogLevel
+	thresholds map[string]time.Duration
+}
+
+// WithTenants returns a copy of the logger resolving the tenant of every
+// statement or message from its context with the resolver, which is called
+// once per statement or message. The tenant is logged as the tenant field and
+// set to TraceRecord.Tenant. An empty tenant means none, so does a panicking
+// resolver. A nil resolver disables tenants. Overrides set with SetTenantLevel
+// and SetTenantSlowThreshold are shared with copies of the returned logger.
+func (l *GormLogger) WithTenants(resolve func(ctx context.Context) string) *GormLogger {
+	c := l.clone()
+	if resolve == nil {
+		c.tenants = nil
+		return c
+	}
+
+	c.tenants = &tenantConfig{
+		resolve:    resolve,
+		levels:     map[string]logger.LogLevel{},
+		thresholds: map[string]time.Duration{},
+	}
+
+	tenants := c.tenants
+	return c.WithContextExtractor(func(ctx context.Context) map[string]any {
+		if tenant := tenants.tenant(ctx); tenant != "" {
+			return map[string]any{"tenant": tenant}
+		}
+
+		return nil
+	})
+}
+
+// SetTenantLevel overrides the log level for statements and messages of the
+// tenant, e.g. to debug a single tenant without raising verbosity globally.
+// Zero level removes the override. It is safe to call while logging and does
+// nothing unless tenants are resolved with WithTenants.
+func (l *GormLogger) SetTenantLevel(tenant string, logLevel logger.LogLevel) {
+	if l.tenants == nil {
+		return
+	}
+
+	l.tenants.mu.Lock()
+	defer l.tenants.mu.Unlock()
+	if logLevel == 0 {
+		delete(l.tenants.levels, tenant)
+		return
+	}
+
+	l.tenants.levels[tenant] = logLevel
+}
+
+// SetTenantSlowThreshold overrides the slow threshold for statements of the
+// tenant. Negative threshold removes the override, zero disables slow query
+// logging for the tenant. It is safe to call while logging and does nothing
+// unless tenants are resolved with WithTenants.
+func (l *GormLogger) SetTenantSlowThreshold(tenant string, slowThreshold time.Duration) {
+	if l.tenants == nil {
+		return
+	}
+
+	l.tenants.mu.Lock()
+	defer l.tenants.mu.Unlock()
+	if slowThreshold < 0 {
+		delete(l.tenants.thresholds, tenant)
+		return
+	}
+
+	l.tenants.thresholds[tenant] = slowThreshold
+}
+
+type resolvedTenantKey struct{}
+
+// withTenant returns a context carrying the tenant of ctx, so it's resolved
+// once for all the lookups of a statement or message.
+func (l *GormLogger) withTenant(ctx context.Context) context.Context {
+	if l.tenants == nil || ctx == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, resolvedTenantKey{}, l.tenants.tenant(ctx))
+}
+
+// tenant returns the tenant of the context, calling the resolver unless it's
+// already resolved, and recovering from its panics.
+func (t *tenantConfig) tenant(ctx context.Context) (tenant string) {
+	if ctx == nil {
+		return ""
+	}
+
+	if tenant, ok := ctx.Value(resolvedTenantKey{}).(string); ok {
+		return tenant
+	}
+
+	defer func() {
+		if recover() != nil {
+			tenant = ""
+		}
+	}()
+
+	return t.resolve(ctx)
+}
+
+// override returns the override of the tenant of the context, if any.
+func override[V any](ctx context.Context, t *tenantConfig, overrides map[string]V) (V, bool) {
+	tenant := t.tenant(ctx)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	v, ok := overrides[tenant]
+	return v, ok && tenant != ""
+}
+
+// thresholdFor returns the slow threshold of statements with the context.
+func (l *GormLogger) thresholdFor(ctx context.Context) time.Duration {
+	if l.tenants != nil && ctx != nil {
+		if slowThreshold, ok := override(ctx, l.tenants, l.tenants.thresholds); ok {
+			return slowThreshold
+		}
+	}
+
+	return l.threshold()
+}
@@ -0,0 +1,85 @@
+package gormzerolog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+type tenantKey struct{}
+
+func TestGormLoggerWithTenants(t *testing.T) {
+	assert := assert.New(t)
+	clock := &testingClock{now: time.Unix(0, 0)}
+	sink := &testingSink{}
+	l := NewGormLogger().WithInfo(sink.factory).WithWarn(sink.factory).WithClock(clock).WithTenants(func(ctx context.Context) string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant
+	})
+	l.SetLogLevel(logger.Warn)
+	l.SlowThreshold(time.Second)
+	l.SetTenantLevel("acme", logger.Info)
+	l.SetTenantSlowThreshold("acme", 10*time.Millisecond)
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+	other := context.WithValue(context.Background(), tenantKey{}, "other")
+	trace := func(ctx context.Context, elapsed time.Duration) {
+		l.Trace(ctx, clock.Now().Add(-elapsed), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	trace(other, 100*time.Millisecond)
+	trace(context.Background(), 100*time.Millisecond)
+	assert.Empty(sink.events)
+
+	trace(acme, time.Millisecond)
+	trace(acme, 100*time.Millisecond)
+	require.Len(t, sink.events, 3)
+	assert.Equal("acme", sink.events[0].fields["tenant"])
+	assert.Equal(true, sink.events[1].fields["slow"])
+	assert.Equal(10.0, sink.events[1].fields["slow_threshold_ms"])
+
+	l.SetTenantLevel("acme", 0)
+	l.SetTenantSlowThreshold("acme", -1)
+	trace(acme, 100*time.Millisecond)
+	assert.Len(sink.events, 3)
+}
+
+func TestGormLoggerWithTenantsResolver(t *testing.T) {
+	assert := assert.New(t)
+	sink := &testingSink{}
+	calls := 0
+	l := NewGormLogger().WithInfo(sink.factory).WithWarn(sink.factory).WithTenants(func(ctx context.Context) string {
+		calls++
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		if tenant == "panic" {
+			panic("test")
+		}
+
+		return tenant
+	})
+	l.SetTenantLevel("acme", logger.Info)
+	l.SetTenantSlowThreshold("acme", time.Millisecond)
+	trace := func(ctx context.Context) {
+		l.Trace(ctx, time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	}
+
+	trace(context.WithValue(context.Background(), tenantKey{}, "acme"))
+	assert.Equal(1, calls, "the tenant should be resolved once per trace")
+	require.Len(t, sink.events, 2)
+	assert.Equal("acme", sink.events[0].fields["tenant"])
+	assert.Equal("acme", sink.events[1].fields["tenant"])
+
+	var nilCtx context.Context
+	assert.NotPanics(func() { trace(nilCtx) })
+	assert.NotPanics(func() { trace(context.WithValue(context.Background(), tenantKey{}, "panic")) })
+	require.Len(t, sink.events, 6)
+	assert.NotContains(sink.events[4].fields, "tenant")
+
+	l = l.WithTenants(nil)
+	assert.NotPanics(func() { trace(context.Background()) })
+	l.SetTenantLevel("acme", logger.Warn)
+	assert.Len(sink.events, 8)
+}
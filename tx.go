@@ -0,0 +1,108 @@
+package gormzerolog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+const (
+	txOutcomeCommit   = "commit"
+	txOutcomeRollback = "rollback"
+)
+
+var (
+	txCounter              atomic.Uint64
+	errTransactionPanicked = errors.New("transaction panicked")
+)
+
+type txGroupKey struct{}
+
+type txGroup struct {
+	id      string
+	seq     atomic.Int64
+	mu      sync.Mutex
+	records []*TraceRecord
+}
+
+// add buffers the record. When the buffer reaches the limit, the buffered
+// records are returned for emission to keep memory usage bounded.
+func (g *txGroup) add(rec *TraceRecord, limit int) []*TraceRecord {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.records = append(g.records, rec)
+	if len(g.records) < limit {
+		return nil
+	}
+
+	records := g.records
+	g.records = nil
+	return records
+}
+
+func (g *txGroup) take() []*TraceRecord {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	records := g.records
+	g.records = nil
+	return records
+}
+
+func txGroupFromContext(ctx context.Context) *txGroup {
+	if ctx == nil {
+		return nil
+	}
+
+	g, _ := ctx.Value(txGroupKey{}).(*txGroup)
+	return g
+}
+
+// WithTransaction returns a context which assigns a new transaction ID to
+// every statement traced with it. The ID is logged as the tx_id field and
+// the sequence number of the statement in the transaction as tx_seq.
+func WithTransaction(ctx context.Context) context.Context {
+	id := strconv.FormatUint(txCounter.Add(1), 10)
+	return context.WithValue(ctx, txGroupKey{}, &txGroup{id: id})
+}
+
+// BufferTransactions enables buffering of traces of statements executed within
+// a transaction context, so they are emitted together when the transaction ends.
+// At most limit statements are buffered, after which the buffer is emitted early.
+// Zero limit disables buffering.
+func (l *GormLogger) BufferTransactions(limit int) {
+	l.txBufferSize = limit
+}
+
+// EndTransaction emits the traces buffered for the transaction context
+// created by WithTransaction. Nil err means the transaction was committed.
+func (l *GormLogger) EndTransaction(ctx context.Context, err error) {
+	g := txGroupFromContext(ctx)
+	if g == nil {
+		return
+	}
+
+	outcome := txOutcomeCommit
+	if err != nil {
+		outcome = txOutcomeRollback
+	}
+
+	for _, rec := range g.take() {
+		rec.TxOutcome = outcome
+		l.dispatch(rec)
+	}
+}
+
+// Transaction runs fc in a database transaction like gorm.DB.Transaction,
+// grouping the traces of its statements under a shared transaction ID.
+func (l *GormLogger) Transaction(ctx context.Context, db *gorm.DB, fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) (err error) {
+	ctx = WithTransaction(ctx)
+	err = errTransactionPanicked
+	defer func() { l.EndTransaction(ctx, err) }()
+	err = db.WithContext(ctx).Transaction(fc, opts...)
+	return err
+}
@@ -0,0 +1,130 @@
+package gormzerolog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormLoggerTransaction(t *testing.T) {
+	trace := func(l *GormLogger, ctx context.Context, sql string) {
+		l.Trace(ctx, time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	t.Run("interleaved", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory)
+		l.BufferTransactions(10)
+		ctxA := WithTransaction(context.Background())
+		ctxB := WithTransaction(context.Background())
+		trace(l, ctxA, "A1")
+		trace(l, ctxB, "B1")
+		trace(l, ctxA, "A2")
+		trace(l, ctxB, "B2")
+		trace(l, context.Background(), "C1")
+		assert.Len(sink.events, 1)
+
+		l.EndTransaction(ctxB, errors.New("test"))
+		l.EndTransaction(ctxA, nil)
+		require.Len(t, sink.events, 5)
+		idA := sink.events[3].added["tx_id"]
+		idB := sink.events[1].added["tx_id"]
+		assert.NotEmpty(idA)
+		assert.NotEmpty(idB)
+		assert.NotEqual(idA, idB)
+		assert.Empty(sink.events[0].added["tx_id"])
+		for i, expected := range []struct {
+			sql, id, outcome string
+			seq              any
+		}{
+			{"C1", "", "", nil},
+			{"B1", idB, txOutcomeRollback, int64(1)},
+			{"B2", idB, txOutcomeRollback, int64(2)},
+			{"A1", idA, txOutcomeCommit, int64(1)},
+			{"A2", idA, txOutcomeCommit, int64(2)},
+		} {
+			e := sink.events[i]
+			assert.Contains(e.msg, expected.sql)
+			assert.Equal(expected.id, e.added["tx_id"])
+			assert.Equal(expected.outcome, e.added["tx_outcome"])
+			assert.Equal(expected.seq, e.fields["tx_seq"])
+		}
+	})
+
+	t.Run("buffer limit", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory)
+		l.BufferTransactions(2)
+		ctx := WithTransaction(context.Background())
+		trace(l, ctx, "S1")
+		assert.Empty(sink.messages())
+		trace(l, ctx, "S2")
+		assert.Len(sink.messages(), 2)
+		trace(l, ctx, "S3")
+		assert.Len(sink.messages(), 2)
+		l.EndTransaction(ctx, nil)
+		msgs := sink.messages()
+		require.Len(t, msgs, 3)
+		assert.Contains(msgs[2], "S3")
+		assert.Empty(sink.events[0].added["tx_outcome"])
+		assert.Equal(txOutcomeCommit, sink.events[2].added["tx_outcome"])
+	})
+
+	t.Run("tagging without buffering", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory)
+		ctx := WithTransaction(context.Background())
+		trace(l, ctx, "S1")
+		require.Len(t, sink.events, 1)
+		assert.NotEmpty(sink.events[0].added["tx_id"])
+		l.EndTransaction(ctx, nil)
+		assert.Len(sink.events, 1)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		assert := assert.New(t)
+		sink := &testingSink{}
+		l := NewGormLogger().WithInfo(sink.factory)
+		l.BufferTransactions(10)
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: l})
+		require.NoError(t, err)
+		type Item struct {
+			ID   uint
+			Name string
+		}
+
+		require.NoError(t, db.AutoMigrate(&Item{}))
+		sink.events = nil
+		err = l.Transaction(context.Background(), db, func(tx *gorm.DB) error {
+			if err := tx.Create(&Item{Name: "a"}).Error; err != nil {
+				return err
+			}
+
+			return tx.Create(&Item{Name: "b"}).Error
+		})
+		require.NoError(t, err)
+		require.Len(t, sink.events, 2)
+		for _, e := range sink.events {
+			assert.Equal(txOutcomeCommit, e.added["tx_outcome"])
+			assert.Equal(sink.events[0].added["tx_id"], e.added["tx_id"])
+		}
+
+		sink.events = nil
+		err = l.Transaction(context.Background(), db, func(tx *gorm.DB) error {
+			tx.Create(&Item{Name: "c"})
+			return errors.New("test")
+		})
+		assert.Error(err)
+		require.Len(t, sink.events, 1)
+		assert.Equal(txOutcomeRollback, sink.events[0].added["tx_outcome"])
+	})
+}
@@ -0,0 +1,108 @@
+package gormzerolog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// inFlight is a watchdog of a running statement.
+type inFlight struct {
+	l     *GormLogger
+	start time.Time
+	timer Timer
+
+	mu   sync.Mutex
+	rec  TraceRecord
+	done bool
+}
+
+// WatchInFlight sets a flag for logging a warning while a statement is
+// still running once it passes the slow threshold, so a statement which
+// hangs is noticed before it completes. The warning is logged at most once
+// per statement and carries the stmt_id, in_flight and elapsed fields and
+// the SQL, if it's already sent to the database. The statement is watched
+// with a timer of the clock if it implements TimerClock. Statements are
+// watched only if the logger is attached with the Plugin.
+func (l *GormLogger) WatchInFlight(b bool) {
+	l.watchInFlight = b
+}
+
+func (l *GormLogger) watchesInFlight() bool {
+	return l.watchInFlight && l.threshold() > 0
+}
+
+// armWatchdog starts watching the statement described by info, executed
+// with the context ctx.
+func (l *GormLogger) armWatchdog(ctx context.Context, info *statementInfo) {
+	threshold := l.thresholdFor(ctx)
+	if !l.watchesInFlight() || info.dryRun || threshold <= 0 {
+		return
+	}
+
+	w := &inFlight{
+		l:     l,
+		start: info.dbStart,
+		rec: TraceRecord{
+			Table:       info.table,
+			Operation:   info.operation,
+			Model:       info.model,
+			StatementID: info.id,
+			ctx:         ctx,
+		},
+	}
+
+	w.timer = l.afterFunc(threshold, w.fire)
+	info.watch = w
+}
+
+// disarmWatchdog stops watching the statement described by info.
+func disarmWatchdog(info *statementInfo) {
+	if info.watch == nil {
+		return
+	}
+
+	w := info.watch
+	info.watch = nil
+	w.mu.Lock()
+	w.done = true
+	w.mu.Unlock()
+	w.timer.Stop()
+}
+
+// executing records the SQL of the statement sent to the database.
+func (w *inFlight) executing(sql string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rec.SQL = sql
+}
+
+// fire logs the warning, unless the statement has completed. The watchdog
+// stays locked while logging, so the warning never follows the completion.
+func (w *inFlight) fire() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done {
+		return
+	}
+
+	w.done = true
+	rec := w.rec
+	if rec.Operation == "" {
+		rec.Operation = sqlOperation(rec.SQL)
+	}
+
+	if rec.Table == "" {
+		rec.Table = sqlTable(rec.SQL)
+	}
+
+	event := w.l.newEventWith(w.rec.ctx, logger.Warn, false)
+	if event == nil {
+		return
+	}
+
+	event = event.Any("in_flight", true).Dur(w.l.fieldNames.Elapsed, w.l.clock.Now().Sub(w.start))
+	w.l.logStatement(event, &rec, w.l.literals.InFlight)
+}
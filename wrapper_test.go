@@ -0,0 +1,32 @@
+package gormzerolog
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// testingRepository emulates a data access layer wrapping gorm calls,
+// which should be skipped while looking for the caller.
+type testingRepository struct {
+	db *gorm.DB
+}
+
+func (r *testingRepository) find(dest any) error {
+	return r.query(dest)
+}
+
+func (r *testingRepository) query(dest any) error {
+	return r.db.Find(dest).Error
+}
+
+// deepTrace traces a statement through depth nested wrapper frames.
+func deepTrace(l *GormLogger, depth int) {
+	if depth == 0 {
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		return
+	}
+
+	deepTrace(l, depth-1)
+}